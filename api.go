@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// apiVersion is the schema version of every /api response. Bump it when a
+// field is renamed or removed; adding fields doesn't need a bump.
+const apiVersion = 1
+
+type apiLeague struct {
+	Version   int            `json:"version"`
+	League    int            `json:"league"`
+	Gameweek  int            `json:"gameweek"`
+	Truncated bool           `json:"truncated"`
+	Standings []apiLeagueRow `json:"standings"`
+}
+
+type apiLeagueRow struct {
+	Rank     int `json:"rank"`
+	LastRank int `json:"last_rank"`
+	apiEntryLive
+}
+
+// apiEntryLive is a manager's live gameweek, on its own or as a league row.
+type apiEntryLive struct {
+	Entry     int      `json:"entry"`
+	TeamName  string   `json:"team_name"`
+	GWTotal   int      `json:"gw_total"`
+	LiveTotal int      `json:"live_total"`
+	PrevTotal int      `json:"prev_total"`
+	BenchPts  int      `json:"bench_points"`
+	Captain   string   `json:"captain"`
+	Chip      string   `json:"chip"`
+	Transfers int      `json:"transfers"`
+	Hits      int      `json:"hits"`
+	Played    int      `json:"played"`
+	Playing   int      `json:"playing"`
+	ToPlay    int      `json:"to_play"`
+	AutoSubs  []string `json:"auto_subs"`
+	Blanks    []string `json:"blanks"`
+}
+
+func newAPILeague(id, week int, rows []row, truncated bool) apiLeague {
+	league := apiLeague{
+		Version:   apiVersion,
+		League:    id,
+		Gameweek:  week,
+		Truncated: truncated,
+		Standings: make([]apiLeagueRow, 0, len(rows)),
+	}
+	for _, r := range rows {
+		league.Standings = append(league.Standings, apiLeagueRow{r.Rank, r.LastRank, newAPIEntryLive(r)})
+	}
+	return league
+}
+
+func newAPIEntryLive(r row) apiEntryLive {
+	return apiEntryLive{
+		Entry:     r.TeamID,
+		TeamName:  r.TeamName,
+		GWTotal:   r.GWTotal,
+		LiveTotal: r.LiveTotal,
+		PrevTotal: r.PrevTotal,
+		BenchPts:  r.BenchPts,
+		Captain:   r.Captain,
+		Chip:      r.Chip,
+		Transfers: r.Transfers,
+		Hits:      r.Hits,
+		Played:    r.Played,
+		Playing:   r.Playing,
+		ToPlay:    r.ToPlay,
+		AutoSubs:  nonNil(r.AutoSubs),
+		Blanks:    nonNil(r.Blanks),
+	}
+}
+
+// nonNil keeps empty lists as [] rather than null in the JSON.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+type apiError struct {
+	Version int    `json:"version"`
+	Status  int    `json:"status"`
+	Error   string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+// renderJSONError is renderError for the JSON API.
+func renderJSONError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	log.Println(err)
+	status, message := errorStatus(err)
+	writeJSON(w, status, apiError{apiVersion, status, message})
+}
+
+type apiManager struct {
+	Version   int              `json:"version"`
+	Entry     int              `json:"entry"`
+	FirstName string           `json:"first_name"`
+	LastName  string           `json:"last_name"`
+	TeamName  string           `json:"team_name"`
+	TeamValue float64          `json:"team_value"`
+	Bank      float64          `json:"bank"`
+	Leagues   []apiLeagueEntry `json:"leagues"`
+	Current   []apiGameweek    `json:"current"`
+	Past      []apiSeason      `json:"past"`
+	Chips     []apiChip        `json:"chips"`
+}
+
+type apiLeagueEntry struct {
+	League   int    `json:"league"`
+	Name     string `json:"name"`
+	Rank     int    `json:"rank"`
+	LastRank int    `json:"last_rank"`
+}
+
+type apiGameweek struct {
+	Event         int     `json:"event"`
+	Points        int     `json:"points"`
+	TotalPoints   int     `json:"total_points"`
+	EventRank     int     `json:"event_rank"`
+	OverallRank   int     `json:"overall_rank"`
+	BenchPts      int     `json:"bench_points"`
+	Transfers     int     `json:"transfers"`
+	TransfersCost int     `json:"transfers_cost"`
+	TeamValue     float64 `json:"team_value"`
+	Bank          float64 `json:"bank"`
+}
+
+type apiSeason struct {
+	Season      string `json:"season"`
+	TotalPoints int    `json:"total_points"`
+	Rank        int    `json:"rank"`
+}
+
+type apiChip struct {
+	Name  string    `json:"name"`
+	Event int       `json:"event"`
+	Time  time.Time `json:"time"`
+}
+
+// millions turns FPL's tenths of a million into millions.
+func millions(tenths int) float64 {
+	return float64(tenths) / 10
+}
+
+func newAPIManager(m managerOutputPageData) apiManager {
+	manager := apiManager{
+		Version:   apiVersion,
+		Entry:     m.ManagerID,
+		FirstName: m.ManagerFirstName,
+		LastName:  m.ManagerLastName,
+		TeamName:  m.TeamName,
+		TeamValue: millions(m.Value),
+		Bank:      millions(m.Bank),
+		Leagues:   make([]apiLeagueEntry, 0, len(m.Leagues)),
+		Current:   make([]apiGameweek, 0, len(m.PastFinishes.Current)),
+		Past:      make([]apiSeason, 0, len(m.PastFinishes.Past)),
+		Chips:     make([]apiChip, 0, len(m.PastFinishes.Chips)),
+	}
+	for _, l := range m.Leagues {
+		manager.Leagues = append(manager.Leagues, apiLeagueEntry{l.LeagueID, l.LeagueName, l.Rank, l.LastRank})
+	}
+	for _, h := range m.PastFinishes.Current {
+		manager.Current = append(manager.Current, apiGameweek{
+			Event:         h.Event,
+			Points:        h.Points,
+			TotalPoints:   h.TotalPoints,
+			EventRank:     h.Rank,
+			OverallRank:   h.OverallRank,
+			BenchPts:      h.PointsOnBench,
+			Transfers:     h.EventTransfers,
+			TransfersCost: h.EventTransfersCost,
+			TeamValue:     millions(h.Value),
+			Bank:          millions(h.Bank),
+		})
+	}
+	for _, p := range m.PastFinishes.Past {
+		manager.Past = append(manager.Past, apiSeason{p.SeasonName, p.TotalPoints, p.Rank})
+	}
+	for _, c := range m.PastFinishes.Chips {
+		manager.Chips = append(manager.Chips, apiChip{c.Name, c.Event, c.Time})
+	}
+	return manager
+}
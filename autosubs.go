@@ -0,0 +1,63 @@
+package main
+
+type substitution struct {
+	Out int
+	In  int
+}
+
+// autoSubs applies FPL's automatic substitutions to a manager's team. Each
+// starter who did not play is replaced by the first bench player, in bench
+// order, who keeps the XI inside every position's SquadMinPlay/SquadMaxPlay
+// and didn't play themselves. A bench player whose match is still to come
+// holds the slot: nobody further down the bench comes on in their place, and
+// they can't come on for a later starter either, so the result is a
+// projection until the gameweek is over. It returns the XI that scores and
+// the substitutions made.
+func autoSubs(snap gameweekSnapshot, gw *gameweek) ([]int, []substitution) {
+	xi := append([]int(nil), snap.Starting...)
+	used := make(map[int]bool)
+	var subs []substitution
+
+	for i, starter := range xi {
+		if !gw.didNotPlay(starter) {
+			continue
+		}
+		for _, sub := range snap.Bench {
+			if used[sub] || gw.didNotPlay(sub) {
+				continue
+			}
+			xi[i] = sub
+			if !gw.validFormation(xi) {
+				xi[i] = starter
+				continue
+			}
+			used[sub] = true
+			if gw.minutes(sub) == 0 {
+				// Still to play: the slot is theirs if they do.
+				xi[i] = starter
+				break
+			}
+			subs = append(subs, substitution{starter, sub})
+			break
+		}
+	}
+	return xi, subs
+}
+
+// validFormation checks an XI against the min and max number of players per
+// position from bootstrap-static.
+func (gw *gameweek) validFormation(xi []int) bool {
+	counts := make(map[int]int)
+	for _, id := range xi {
+		if player, ok := gw.element(id); ok {
+			counts[player.ElementType]++
+		}
+	}
+	for _, elementType := range gw.types {
+		n := counts[elementType.ID]
+		if n < elementType.SquadMinPlay || n > elementType.SquadMaxPlay {
+			return false
+		}
+	}
+	return true
+}
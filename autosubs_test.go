@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// setTestBootstrap loads a squad of fifteen: 1 and 12 are goalkeepers, 2-5
+// and 13 defenders, 6-9 and 14 midfielders, 10, 11 and 15 forwards. 13 plays
+// for team 2 and everyone else for team 1.
+func setTestBootstrap() {
+	types := map[int]int{1: 1, 12: 1, 2: 2, 3: 2, 4: 2, 5: 2, 13: 2, 6: 3, 7: 3, 8: 3, 9: 3, 14: 3, 10: 4, 11: 4, 15: 4}
+	data := &fpl.Bootstrap{
+		ElementTypes: []fpl.ElementType{
+			{ID: 1, SquadMinPlay: 1, SquadMaxPlay: 1},
+			{ID: 2, SquadMinPlay: 3, SquadMaxPlay: 5},
+			{ID: 3, SquadMinPlay: 2, SquadMaxPlay: 5},
+			{ID: 4, SquadMinPlay: 1, SquadMaxPlay: 3},
+		},
+	}
+	for id := 1; id <= 15; id++ {
+		team := 1
+		if id == 13 {
+			team = 2
+		}
+		data.Elements = append(data.Elements, fpl.Element{ID: id, ElementType: types[id], Team: team})
+	}
+	setBootstrap(data)
+}
+
+func testSnapshot() gameweekSnapshot {
+	return gameweekSnapshot{
+		Starting: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		Bench:    []int{12, 13, 14, 15},
+	}
+}
+
+// testGameweek has team 1's fixture finished, and team 2's too if done.
+// Everyone on team 1 played 90 minutes apart from those in dnp; minutes
+// overrides anyone's minutes.
+func testGameweek(done bool, dnp []int, minutes map[int]int) *gameweek {
+	live := make(liveIndex)
+	for id := 1; id <= 15; id++ {
+		m := 90
+		if id == 13 {
+			m = 0
+		}
+		live[id] = fpl.LiveElement{ID: id, Stats: fpl.LiveStats{Minutes: m}}
+	}
+	for _, id := range dnp {
+		live[id] = fpl.LiveElement{ID: id}
+	}
+	for id, m := range minutes {
+		live[id] = fpl.LiveElement{ID: id, Stats: fpl.LiveStats{Minutes: m}}
+	}
+	fixtures := []fpl.Fixture{
+		{ID: 1, TeamH: 1, TeamA: 3, Started: true, Finished: true, FinishedProvisional: true},
+		{ID: 2, TeamH: 2, TeamA: 4, Started: done, Finished: done, FinishedProvisional: done},
+	}
+	return newGameweek(1, live, fixtures)
+}
+
+func TestAutoSubs(t *testing.T) {
+	setTestBootstrap()
+	tests := []struct {
+		name string
+		gw   *gameweek
+		want []substitution
+	}{
+		{
+			// 12 is a goalkeeper and can't replace 7, and 13 hasn't
+			// played yet, so 14 waits on him rather than coming on.
+			name: "earlier bench player still to play holds the slot",
+			gw:   testGameweek(false, []int{7}, nil),
+			want: nil,
+		},
+		{
+			name: "bench player who has come on takes the slot",
+			gw:   testGameweek(false, []int{7}, map[int]int{13: 30}),
+			want: []substitution{{7, 13}},
+		},
+		{
+			name: "bench player who didn't play is passed over",
+			gw:   testGameweek(true, []int{7}, nil),
+			want: []substitution{{7, 14}},
+		},
+		{
+			name: "goalkeeper is only replaced by the bench goalkeeper",
+			gw:   testGameweek(true, []int{1, 3}, map[int]int{13: 90}),
+			want: []substitution{{1, 12}, {3, 13}},
+		},
+		{
+			name: "goalkeeper isn't replaced by an outfield player",
+			gw:   testGameweek(true, []int{1, 12}, map[int]int{13: 90}),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := autoSubs(testSnapshot(), tt.gw)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("autoSubs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayerCounts(t *testing.T) {
+	setTestBootstrap()
+	// team is the XI at 1, captain 6 at 2 and the bench at 0, with changes.
+	team := func(changes map[int]int) map[int]int {
+		m := make(map[int]int)
+		for id := 1; id <= 15; id++ {
+			if id <= 11 {
+				m[id] = 1
+			} else {
+				m[id] = 0
+			}
+		}
+		m[6] = 2
+		for id, multiplier := range changes {
+			m[id] = multiplier
+		}
+		return m
+	}
+	subbed := team(map[int]int{7: 0, 13: 1})
+	bboost := team(map[int]int{12: 1, 13: 1, 14: 1, 15: 1})
+
+	tests := []struct {
+		name        string
+		multipliers map[int]int
+		team2       string // not started, playing, on (13 has come on) or done
+		want        [3]int
+	}{
+		{"starting XI, bench doesn't count", team(nil), "playing", [3]int{11, 0, 0}},
+		{"subbed on player still to play", subbed, "not started", [3]int{10, 0, 1}},
+		{"unused sub in a match in progress", subbed, "playing", [3]int{10, 0, 1}},
+		{"subbed on player on the pitch", subbed, "on", [3]int{10, 1, 0}},
+		{"bench boost counts all fifteen", bboost, "on", [3]int{14, 1, 0}},
+		{"bench boost, all done", bboost, "done", [3]int{15, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := make(liveIndex)
+			for id := 1; id <= 15; id++ {
+				live[id] = fpl.LiveElement{ID: id, Stats: fpl.LiveStats{Minutes: 90}}
+			}
+			second := fpl.Fixture{ID: 2, TeamH: 2, TeamA: 4}
+			switch tt.team2 {
+			case "playing":
+				second.Started = true
+				live[13] = fpl.LiveElement{ID: 13}
+			case "on":
+				second.Started = true
+				var on fpl.LiveElement
+				json.Unmarshal([]byte(`{"id": 13, "stats": {"minutes": 30},
+					"explain": [{"fixture": 2, "stats": [{"identifier": "minutes", "value": 30}]}]}`), &on)
+				live[13] = on
+			case "done":
+				second.Started, second.Finished, second.FinishedProvisional = true, true, true
+			default:
+				live[13] = fpl.LiveElement{ID: 13}
+			}
+			gw := newGameweek(1, live, []fpl.Fixture{
+				{ID: 1, TeamH: 1, TeamA: 3, Started: true, Finished: true, FinishedProvisional: true},
+				second,
+			})
+			played, playing, toPlay := gw.playerCounts(tt.multipliers)
+			if got := [3]int{played, playing, toPlay}; got != tt.want {
+				t.Errorf("playerCounts() = %v, want played, playing, to play %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// bootstrapRefresh is how often bootstrap-static is re-fetched for price
+// changes and injury news, set with BOOTSTRAP_REFRESH (e.g. "30m").
+var bootstrapRefresh = time.Hour
+
+// stateMu guards fplData, elementsByID and currentGw, which the refresher
+// swaps while handlers read them. Read them through getBootstrap, getElements
+// and getCurrentGw.
+var stateMu sync.RWMutex
+
+// elementsByID is fplData's players by ID.
+var elementsByID map[int]fpl.Element
+
+func getBootstrap() *fpl.Bootstrap {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return fplData
+}
+
+// getElements returns the bootstrap data and its players by ID, both from
+// the same fetch. Neither is modified once set.
+func getElements() (*fpl.Bootstrap, map[int]fpl.Element) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return fplData, elementsByID
+}
+
+func getCurrentGw() int {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return currentGw
+}
+
+// setBootstrap swaps in freshly fetched bootstrap data and the gameweek it
+// makes current, logging when the gameweek rolls over.
+func setBootstrap(data *fpl.Bootstrap) {
+	gw := findCurrentGw(data, time.Now())
+	elements := make(map[int]fpl.Element, len(data.Elements))
+	for _, element := range data.Elements {
+		elements[element.ID] = element
+	}
+
+	stateMu.Lock()
+	previous := currentGw
+	fplData = data
+	elementsByID = elements
+	currentGw = gw
+	stateMu.Unlock()
+
+	if gw != previous {
+		log.Printf("current gameweek: %v (was %v)", gw, previous)
+	}
+}
+
+// findCurrentGw is the event flagged IsCurrent, or the IsNext event once its
+// DeadlineTime has passed, since FPL takes a while to move the flags on.
+func findCurrentGw(data *fpl.Bootstrap, now time.Time) int {
+	var gw int
+	for _, event := range data.Events {
+		if event.IsCurrent {
+			gw = event.ID
+		}
+		if event.IsNext && !event.DeadlineTime.IsZero() && now.After(event.DeadlineTime) {
+			return event.ID
+		}
+	}
+	return gw
+}
+
+// nextDeadline is the deadline of the IsNext event, zero if there is none.
+func nextDeadline(data *fpl.Bootstrap) time.Time {
+	for _, event := range data.Events {
+		if event.IsNext {
+			return event.DeadlineTime
+		}
+	}
+	return time.Time{}
+}
+
+// refreshBootstrap re-fetches bootstrap-static every bootstrapRefresh, and
+// just after the next deadline so the new gameweek shows up straight away. A
+// failed fetch keeps the old data and tries again on the next tick.
+func refreshBootstrap(ctx context.Context) {
+	for {
+		wait := bootstrapRefresh
+		if deadline := nextDeadline(getBootstrap()); !deadline.IsZero() {
+			if untilDeadline := time.Until(deadline) + time.Minute; untilDeadline > 0 && untilDeadline < wait {
+				wait = untilDeadline
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		data, err := client.Bootstrap(fpl.NoCache(ctx))
+		if err != nil {
+			log.Println("refreshing bootstrap:", err)
+			continue
+		}
+		setBootstrap(data)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+func TestFindCurrentGw(t *testing.T) {
+	now := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		events []fpl.Event
+		want   int
+	}{
+		{
+			name: "IsCurrent",
+			events: []fpl.Event{
+				{ID: 3, IsCurrent: true},
+				{ID: 4, IsNext: true, DeadlineTime: now.Add(time.Hour)},
+			},
+			want: 3,
+		},
+		{
+			name: "IsNext past its deadline",
+			events: []fpl.Event{
+				{ID: 3, IsCurrent: true},
+				{ID: 4, IsNext: true, DeadlineTime: now.Add(-time.Minute)},
+			},
+			want: 4,
+		},
+		{
+			name: "IsNext with no deadline",
+			events: []fpl.Event{
+				{ID: 3, IsCurrent: true},
+				{ID: 4, IsNext: true},
+			},
+			want: 3,
+		},
+		{
+			name: "IsCurrent only, last gameweek",
+			events: []fpl.Event{
+				{ID: 37, IsPrevious: true},
+				{ID: 38, IsCurrent: true},
+			},
+			want: 38,
+		},
+		{
+			name: "pre-season, IsNext before its deadline",
+			events: []fpl.Event{
+				{ID: 1, IsNext: true, DeadlineTime: now.Add(24 * time.Hour)},
+				{ID: 2},
+			},
+			want: 0,
+		},
+		{
+			name:   "no flags",
+			events: []fpl.Event{{ID: 1}, {ID: 2}},
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findCurrentGw(&fpl.Bootstrap{Events: tt.events}, now); got != tt.want {
+				t.Errorf("findCurrentGw() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
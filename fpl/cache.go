@@ -0,0 +1,106 @@
+package fpl
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache stores raw API responses keyed by request path. Get returns entries
+// even once they have expired, with their expiry, so the client can decide
+// whether stale data is still useful. A zero expiry never expires.
+//
+// LRUCache is the in-memory implementation; a disk or Redis backed Cache can
+// be plugged into Client.Cache instead.
+type Cache interface {
+	Get(key string) (value []byte, expires time.Time, ok bool)
+	Set(key string, value []byte, expires time.Time)
+}
+
+// Forever is a CachePolicy TTL for responses that can never change.
+const Forever time.Duration = -1
+
+// CachePolicy is how long each endpoint's responses are cached. A zero TTL
+// means the endpoint is not cached.
+type CachePolicy struct {
+	Bootstrap time.Duration
+	// Live is used while a match is in progress, LiveIdle otherwise.
+	Live     time.Duration
+	LiveIdle time.Duration
+	Fixtures time.Duration
+	// Picks is for the current gameweek, PastPicks for finished ones.
+	Picks          time.Duration
+	PastPicks      time.Duration
+	Standings      time.Duration
+	Entry          time.Duration
+	EntryHistory   time.Duration
+	ElementSummary time.Duration
+}
+
+// DefaultCachePolicy suits a live leaderboard: the live feed is at most a
+// minute old during matches and picks for past gameweeks never expire.
+var DefaultCachePolicy = CachePolicy{
+	Bootstrap:      2 * time.Hour,
+	Live:           time.Minute,
+	LiveIdle:       10 * time.Minute,
+	Fixtures:       time.Minute,
+	Picks:          5 * time.Minute,
+	PastPicks:      Forever,
+	Standings:      5 * time.Minute,
+	Entry:          10 * time.Minute,
+	EntryHistory:   10 * time.Minute,
+	ElementSummary: time.Hour,
+}
+
+// LRUCache is an in-memory Cache holding at most size entries, evicting the
+// least recently used.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	c.order.MoveToFront(e)
+	entry := e.Value.(*lruEntry)
+	return entry.value, entry.expires, true
+}
+
+func (c *LRUCache) Set(key string, value []byte, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		entry := e.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key, value, expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
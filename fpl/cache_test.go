@@ -0,0 +1,94 @@
+package fpl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheEviction(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", []byte("1"), time.Time{})
+	c.Set("b", []byte("2"), time.Time{})
+	// Reading a makes b the least recently used.
+	c.Get("a")
+	c.Set("c", []byte("3"), time.Time{})
+
+	if _, _, ok := c.Get("b"); ok {
+		t.Error("b still cached, want it evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, _, ok := c.Get(key); !ok {
+			t.Errorf("%v evicted, want it cached", key)
+		}
+	}
+
+	// Overwriting an entry doesn't grow the cache.
+	c.Set("a", []byte("4"), time.Time{})
+	if v, _, _ := c.Get("a"); string(v) != "4" {
+		t.Errorf("Get(a) = %s, want 4", v)
+	}
+	if _, _, ok := c.Get("c"); !ok {
+		t.Error("c evicted by overwriting a")
+	}
+}
+
+func TestLRUCacheReturnsExpired(t *testing.T) {
+	c := NewLRUCache(1)
+	expires := time.Now().Add(-time.Minute)
+	c.Set("a", []byte("1"), expires)
+	v, got, ok := c.Get("a")
+	if !ok || string(v) != "1" || !got.Equal(expires) {
+		t.Errorf("Get(a) = %s, %v, %v; want the expired entry", v, got, ok)
+	}
+}
+
+func TestFetchCache(t *testing.T) {
+	s := newStatusServer(t, http.StatusOK)
+	c := NewClient(s.URL, nil)
+	c.Cache = NewLRUCache(10)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		path     string
+		ttl      time.Duration
+		requests int // made so far, after this fetch
+	}{
+		{"first fetch misses", ctx, "a/", time.Hour, 1},
+		{"fresh entry hits", ctx, "a/", time.Hour, 1},
+		{"NoCache bypasses a fresh entry", NoCache(ctx), "a/", time.Hour, 2},
+		{"zero TTL isn't cached", ctx, "b/", 0, 3},
+		{"zero TTL again", ctx, "b/", 0, 4},
+		{"Forever is cached", ctx, "c/", Forever, 5},
+		{"Forever hits", ctx, "c/", Forever, 5},
+	}
+	for _, tt := range tests {
+		if _, err := c.fetch(tt.ctx, tt.path, tt.ttl); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if got := s.requests(); got != tt.requests {
+			t.Errorf("%v: %v requests so far, want %v", tt.name, got, tt.requests)
+		}
+	}
+
+	if _, expires, ok := c.Cache.Get("c/"); !ok || !expires.IsZero() {
+		t.Errorf("Forever entry expires %v, want zero", expires)
+	}
+	if _, _, ok := c.Cache.Get("b/"); ok {
+		t.Error("zero TTL response was cached")
+	}
+	want := CacheStats{Hits: 2, Misses: 3}
+	if got := c.CacheStats(); got != want {
+		t.Errorf("CacheStats() = %+v, want %+v", got, want)
+	}
+
+	// An expired entry is refetched.
+	c.Cache.Set("a/", []byte(`{}`), time.Now().Add(-time.Second))
+	c.fetch(ctx, "a/", time.Hour)
+	if got := s.requests(); got != 6 {
+		t.Errorf("%v requests after an expired fetch, want 6", got)
+	}
+}
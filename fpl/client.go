@@ -0,0 +1,321 @@
+// Package fpl is a small client for the Fantasy Premier League API.
+package fpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBaseURL is the public FPL API host.
+const DefaultBaseURL = "https://fantasy.premierleague.com/api/"
+
+// The FPL API refuses requests without a browser-ish user agent.
+const defaultUserAgent = "PostmanRuntime/7.18.0"
+
+// ErrNotFound matches errors for endpoints the API answers with 404, such as
+// an unknown league or manager.
+var ErrNotFound = errors.New("fpl: not found")
+
+// StatusError is returned when the API answers with a non-2xx status.
+type StatusError struct {
+	Path       string
+	StatusCode int
+	RetryAfter time.Duration
+	// Challenge is set when Cloudflare answered with a bot challenge.
+	Challenge bool
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fpl: %v returned %v %v", e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports a 404 as ErrNotFound.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Client fetches and decodes FPL API endpoints. BaseURL can point at a local
+// mock server or a caching proxy instead of the public API.
+//
+// With a Cache set, responses are cached for as long as Policy says.
+// CurrentEvent and InPlay, when set, let the policy tell finished gameweeks
+// from the current one and whether the live feed is still moving.
+//
+// Requests wait on Limiter and are retried up to Retries times when the API
+// is rate limiting or failing. While Breaker is open no requests are made and
+// stale cached responses are served where there are any.
+type Client struct {
+	// stats comes first to keep it 64-bit aligned for sync/atomic.
+	stats cacheCounters
+
+	BaseURL    string
+	HTTPClient *http.Client
+	UserAgent  string
+	Limiter    *Limiter
+	Retries    int
+	Breaker    *Breaker
+
+	Cache        Cache
+	Policy       CachePolicy
+	CurrentEvent func() int
+	InPlay       func() bool
+
+	flights flightGroup
+}
+
+// CacheStats counts how fetches were answered since the Client was made.
+type CacheStats struct {
+	Hits   uint64 // fresh from the cache
+	Misses uint64 // from the API
+	Stale  uint64 // misses answered from the cache after the API failed
+}
+
+type cacheCounters struct {
+	hits, misses, stale uint64
+}
+
+// CacheStats reports how many fetches the cache has answered.
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.stats.hits),
+		Misses: atomic.LoadUint64(&c.stats.misses),
+		Stale:  atomic.LoadUint64(&c.stats.stale),
+	}
+}
+
+// NewClient returns a Client for baseURL. An empty baseURL means
+// DefaultBaseURL and a nil httpClient means http.DefaultClient.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+		UserAgent:  defaultUserAgent,
+		Limiter:    NewLimiter(10, 20),
+		Retries:    3,
+		Breaker:    NewBreaker(5, 30*time.Second),
+		Policy:     DefaultCachePolicy,
+	}
+}
+
+// Bootstrap fetches bootstrap-static.
+func (c *Client) Bootstrap(ctx context.Context) (*Bootstrap, error) {
+	var v Bootstrap
+	if err := c.get(ctx, "bootstrap-static/", c.Policy.Bootstrap, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// EntryPicks fetches a manager's picks for a gameweek.
+func (c *Client) EntryPicks(ctx context.Context, entry, event int) (*Picks, error) {
+	var v Picks
+	if err := c.get(ctx, fmt.Sprintf("entry/%v/event/%v/picks/", entry, event), c.picksTTL(event), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// EventLive fetches the live points feed for a gameweek.
+func (c *Client) EventLive(ctx context.Context, event int) (*Live, error) {
+	var v Live
+	if err := c.get(ctx, fmt.Sprintf("event/%v/live/", event), c.liveTTL(event), &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Fixtures fetches the fixtures of a gameweek.
+func (c *Client) Fixtures(ctx context.Context, event int) ([]Fixture, error) {
+	var v []Fixture
+	if err := c.get(ctx, fmt.Sprintf("fixtures/?event=%v", event), c.Policy.Fixtures, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Entry fetches a manager's profile.
+func (c *Client) Entry(ctx context.Context, entry int) (*Entry, error) {
+	var v Entry
+	if err := c.get(ctx, fmt.Sprintf("entry/%v/", entry), c.Policy.Entry, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// EntryHistory fetches a manager's current and past season history.
+func (c *Client) EntryHistory(ctx context.Context, entry int) (*EntryHistory, error) {
+	var v EntryHistory
+	if err := c.get(ctx, fmt.Sprintf("entry/%v/history/", entry), c.Policy.EntryHistory, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ClassicStandings fetches one page of a classic league's standings and one
+// page of its new entries. Pages start at 1.
+func (c *Client) ClassicStandings(ctx context.Context, league, standingsPage, newEntriesPage int) (*ClassicStandings, error) {
+	var v ClassicStandings
+	path := fmt.Sprintf("leagues-classic/%v/standings/?page_standings=%v&page_new_entries=%v", league, standingsPage, newEntriesPage)
+	if err := c.get(ctx, path, c.Policy.Standings, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ElementSummary fetches a player's fixtures and history.
+func (c *Client) ElementSummary(ctx context.Context, element int) (*ElementSummary, error) {
+	var v ElementSummary
+	if err := c.get(ctx, fmt.Sprintf("element-summary/%v/", element), c.Policy.ElementSummary, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+type noCacheKey struct{}
+
+// NoCache returns a context whose requests skip cached responses, such as a
+// refresh that must see the latest data. Fresh responses are still cached.
+func NoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// picksTTL keeps picks for finished gameweeks forever; they can't change.
+func (c *Client) picksTTL(event int) time.Duration {
+	if c.CurrentEvent != nil && event < c.CurrentEvent() {
+		return c.Policy.PastPicks
+	}
+	return c.Policy.Picks
+}
+
+// liveTTL refreshes the live feed often only while it can still change.
+func (c *Client) liveTTL(event int) time.Duration {
+	if c.CurrentEvent != nil && event < c.CurrentEvent() {
+		return c.Policy.LiveIdle
+	}
+	if c.InPlay != nil && !c.InPlay() {
+		return c.Policy.LiveIdle
+	}
+	return c.Policy.Live
+}
+
+func (c *Client) get(ctx context.Context, path string, ttl time.Duration, v interface{}) error {
+	body, err := c.fetch(ctx, path, ttl)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding %v: %w", path, err)
+	}
+	return nil
+}
+
+// fetch returns the body for path from the cache when it's fresh, otherwise
+// from the API, caching it for ttl. Concurrent fetches of the same path share
+// one upstream call. If the API is unhealthy a stale cached body is returned
+// instead of the error.
+func (c *Client) fetch(ctx context.Context, path string, ttl time.Duration) ([]byte, error) {
+	if c.Cache == nil || ttl == 0 {
+		return c.flights.do(ctx, path, func(ctx context.Context) ([]byte, error) {
+			return c.call(ctx, path)
+		})
+	}
+	stale, expires, cached := c.Cache.Get(path)
+	if cached && ctx.Value(noCacheKey{}) == nil && (expires.IsZero() || time.Now().Before(expires)) {
+		atomic.AddUint64(&c.stats.hits, 1)
+		return stale, nil
+	}
+	atomic.AddUint64(&c.stats.misses, 1)
+	body, err := c.flights.do(ctx, path, func(ctx context.Context) ([]byte, error) {
+		body, err := c.call(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		var expires time.Time
+		if ttl != Forever {
+			expires = time.Now().Add(ttl)
+		}
+		c.Cache.Set(path, body, expires)
+		return body, nil
+	})
+	if err != nil && cached && (retryable(err) || errors.Is(err, ErrUnavailable)) {
+		log.Printf("fpl: serving stale %v: %v", path, err)
+		atomic.AddUint64(&c.stats.stale, 1)
+		return stale, nil
+	}
+	return body, err
+}
+
+// call makes the request through the breaker and limiter, retrying with
+// backoff while the API is rate limiting or failing.
+func (c *Client) call(ctx context.Context, path string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if c.Breaker != nil && !c.Breaker.Allow() {
+			return nil, ErrUnavailable
+		}
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		body, err := c.do(ctx, path)
+		if err == nil || !retryable(err) {
+			if c.Breaker != nil {
+				c.Breaker.Record(true)
+			}
+			return body, err
+		}
+		if c.Breaker != nil {
+			c.Breaker.Record(false)
+		}
+		wait := backoff(err, attempt)
+		if attempt >= c.Retries || wait > maxRetryWait {
+			return nil, err
+		}
+		log.Printf("fpl: retrying %v in %v: %v", path, wait.Round(time.Millisecond), err)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			Challenge:  resp.Header.Get("Cf-Mitigated") == "challenge",
+		}
+	}
+	return ioutil.ReadAll(resp.Body)
+}
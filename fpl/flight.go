@@ -0,0 +1,55 @@
+package fpl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flightTimeout bounds a shared upstream call, which outlives any one caller.
+const flightTimeout = time.Minute
+
+// flightGroup coalesces concurrent fetches of the same path into one upstream
+// call whose result every caller shares.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// do calls fn for path unless a call for path is already in flight, in which
+// case it waits for that one. fn runs on its own context so one caller giving
+// up doesn't fail the others; a caller whose ctx ends stops waiting.
+func (g *flightGroup) do(ctx context.Context, path string, fn func(context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	f, ok := g.calls[path]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		g.calls[path] = f
+		go func() {
+			fctx, cancel := context.WithTimeout(context.Background(), flightTimeout)
+			defer cancel()
+			f.body, f.err = fn(fctx)
+			g.mu.Lock()
+			delete(g.calls, path)
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.body, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
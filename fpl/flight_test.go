@@ -0,0 +1,68 @@
+package fpl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCoalesces(t *testing.T) {
+	s := newStatusServer(t, http.StatusOK)
+	release := s.hold()
+	c := NewClient(s.URL, nil)
+
+	const callers = 10
+	var started, wg sync.WaitGroup
+	bodies := make([][]byte, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			bodies[i], errs[i] = c.fetch(context.Background(), "bootstrap-static/", 0)
+		}(i)
+	}
+
+	// One more caller gives up while the request is still held.
+	ctx, cancel := context.WithCancel(context.Background())
+	gaveUp := make(chan error, 1)
+	go func() {
+		_, err := c.fetch(ctx, "bootstrap-static/", 0)
+		gaveUp <- err
+	}()
+
+	started.Wait()
+	for deadline := time.Now().Add(5 * time.Second); s.requests() == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Give the callers time to join the flight that's now in the handler.
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-gaveUp:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled fetch error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled fetch still waiting on the held request")
+	}
+
+	release()
+	wg.Wait()
+	for i := range bodies {
+		if errs[i] != nil {
+			t.Errorf("fetch %v error = %v", i, errs[i])
+		} else if string(bodies[i]) != `{"fresh":true}` {
+			t.Errorf("fetch %v = %s", i, bodies[i])
+		}
+	}
+	if got := s.requests(); got != 1 {
+		t.Errorf("made %v requests, want 1", got)
+	}
+}
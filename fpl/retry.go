@@ -0,0 +1,112 @@
+package fpl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned without calling the API while the circuit
+// breaker is open.
+var ErrUnavailable = errors.New("fpl: upstream unavailable")
+
+const (
+	retryBase = 500 * time.Millisecond
+	// maxRetryWait is the longest the client waits before a retry, including
+	// a Retry-After; anything longer is left to the circuit breaker.
+	maxRetryWait = 10 * time.Second
+)
+
+// retryable reports whether err means the API is struggling rather than that
+// the request was wrong: rate limiting, a Cloudflare challenge, a 5xx or a
+// network error.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode == http.StatusTooManyRequests || se.StatusCode >= 500 || se.Challenge
+	}
+	return !errors.Is(err, ErrUnavailable) && !errors.Is(err, context.Canceled)
+}
+
+// backoff is how long to wait before retry attempt n (from 0): the response's
+// Retry-After if it sent one, otherwise exponential with full jitter.
+func backoff(err error, n int) time.Duration {
+	var se *StatusError
+	if errors.As(err, &se) && se.RetryAfter > 0 {
+		return se.RetryAfter
+	}
+	d := retryBase << n
+	if d > maxRetryWait {
+		d = maxRetryWait
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// parseRetryAfter reads a Retry-After header, in seconds or as a date.
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// Breaker stops calls to the API after threshold consecutive failures and
+// lets one through again after cooldown. A failure then reopens it and a
+// success closes it.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call may be made.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) {
+		return false
+	}
+	// Half open: let this call through and hold the rest until it's back.
+	b.openUntil = time.Now().Add(b.cooldown)
+	return true
+}
+
+// Record counts the outcome of a call.
+func (b *Breaker) Record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		if b.failures >= b.threshold {
+			log.Printf("fpl: upstream recovered, closing circuit")
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures == b.threshold {
+		log.Printf("fpl: %v consecutive failures, opening circuit for %v", b.failures, b.cooldown)
+	}
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,246 @@
+package fpl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// statusServer answers each request with the next of statuses, repeating the
+// last one once they run out, and counts the requests. While held, requests
+// wait to be answered until release is closed.
+type statusServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	hits     int
+	release  chan struct{}
+}
+
+func newStatusServer(t *testing.T, statuses ...int) *statusServer {
+	s := &statusServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		status := s.statuses[len(s.statuses)-1]
+		if s.hits < len(s.statuses) {
+			status = s.statuses[s.hits]
+		}
+		s.hits++
+		release := s.release
+		s.mu.Unlock()
+		if release != nil {
+			<-release
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"fresh":true}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// hold makes requests wait until the returned func is called.
+func (s *statusServer) hold() func() {
+	release := make(chan struct{})
+	s.mu.Lock()
+	s.release = release
+	s.mu.Unlock()
+	return func() { close(release) }
+}
+
+func (s *statusServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits
+}
+
+func TestCallRetries(t *testing.T) {
+	s := newStatusServer(t, http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK)
+	c := NewClient(s.URL, nil)
+
+	start := time.Now()
+	body, err := c.call(context.Background(), "bootstrap-static/")
+	if err != nil {
+		t.Fatalf("call() error = %v", err)
+	}
+	if string(body) != `{"fresh":true}` {
+		t.Errorf("call() = %s", body)
+	}
+	if got := s.requests(); got != 3 {
+		t.Errorf("made %v requests, want 3", got)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("call() returned after %v, before the 429's Retry-After", elapsed)
+	}
+}
+
+func TestCallGivesUp(t *testing.T) {
+	s := newStatusServer(t, http.StatusServiceUnavailable)
+	c := NewClient(s.URL, nil)
+	c.Retries = 1
+
+	_, err := c.call(context.Background(), "bootstrap-static/")
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("call() error = %v, want a 503", err)
+	}
+	if got := s.requests(); got != 2 {
+		t.Errorf("made %v requests, want 2", got)
+	}
+}
+
+func TestCallDoesNotRetryNotFound(t *testing.T) {
+	s := newStatusServer(t, http.StatusNotFound)
+	c := NewClient(s.URL, nil)
+
+	_, err := c.call(context.Background(), "entry/1/")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("call() error = %v, want ErrNotFound", err)
+	}
+	if got := s.requests(); got != 1 {
+		t.Errorf("made %v requests, want 1", got)
+	}
+}
+
+func TestCallBreakerOpens(t *testing.T) {
+	s := newStatusServer(t, http.StatusServiceUnavailable)
+	c := NewClient(s.URL, nil)
+	c.Retries = 0
+	c.Breaker = NewBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.call(context.Background(), "bootstrap-static/"); errors.Is(err, ErrUnavailable) {
+			t.Fatalf("call %v: breaker open before the threshold", i)
+		}
+	}
+	if _, err := c.call(context.Background(), "bootstrap-static/"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("call() error = %v, want ErrUnavailable", err)
+	}
+	if got := s.requests(); got != 2 {
+		t.Errorf("made %v requests, want 2", got)
+	}
+}
+
+func TestFetchServesStale(t *testing.T) {
+	s := newStatusServer(t, http.StatusServiceUnavailable)
+	c := NewClient(s.URL, nil)
+	c.Retries = 0
+	c.Breaker = NewBreaker(1, time.Minute)
+	c.Cache = NewLRUCache(10)
+	c.Cache.Set("bootstrap-static/", []byte(`{"stale":true}`), time.Now().Add(-time.Minute))
+
+	// The first fetch trips the breaker with a 503, the second never gets
+	// past it; both fall back to the expired body.
+	for i := 0; i < 2; i++ {
+		body, err := c.fetch(context.Background(), "bootstrap-static/", time.Minute)
+		if err != nil {
+			t.Fatalf("fetch %v: error = %v", i, err)
+		}
+		if string(body) != `{"stale":true}` {
+			t.Errorf("fetch %v = %s, want the stale body", i, body)
+		}
+	}
+	if _, err := c.call(context.Background(), "bootstrap-static/"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("call() error = %v, want ErrUnavailable", err)
+	}
+	if got := s.requests(); got != 1 {
+		t.Errorf("made %v requests, want 1", got)
+	}
+
+	// With nothing cached the error gets through.
+	if _, err := c.fetch(context.Background(), "fixtures/", time.Minute); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("fetch() uncached error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := NewBreaker(2, 20*time.Millisecond)
+	b.Record(false)
+	if !b.Allow() {
+		t.Fatal("open after one failure, want closed below the threshold")
+	}
+	b.Record(false)
+	if b.Allow() {
+		t.Fatal("closed after the threshold, want open")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("still open after the cooldown, want one call let through")
+	}
+	if b.Allow() {
+		t.Fatal("let a second call through while half open")
+	}
+	b.Record(true)
+	if !b.Allow() {
+		t.Fatal("open after a success, want closed")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	limited := &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}
+	if got := backoff(limited, 0); got != 3*time.Second {
+		t.Errorf("backoff() with Retry-After = %v, want 3s", got)
+	}
+
+	failed := &StatusError{StatusCode: http.StatusServiceUnavailable}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, retryBase},
+		{2, 4 * retryBase},
+		{10, maxRetryWait},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := backoff(failed, tt.attempt); got < 0 || got >= tt.max {
+				t.Fatalf("backoff(%v) = %v, want [0, %v)", tt.attempt, got, tt.max)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"", 0, 0},
+		{"5", 5 * time.Second, 5 * time.Second},
+		{"soon", 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %v, want [%v, %v]", tt.header, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(50, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst goes straight through; the other two wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("4 requests at 50/s with a burst of 2 took %v, want at least 40ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() on a cancelled ctx = %v, want context.Canceled", err)
+	}
+}
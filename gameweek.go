@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// gameweek is the live state of a gameweek: the live feed, the fixtures and
+// the provisional bonus worked out from them. It is fetched once per league
+// render, every manager is scored from it and it is read-only once built. It
+// keeps the bootstrap players it was built with, so a render isn't split
+// across a bootstrap refresh.
+type gameweek struct {
+	Event    int
+	Live     liveIndex
+	Fixtures []fpl.Fixture
+	Bonus    provisionalBonus
+	byID     map[int]fpl.Fixture
+	byTeam   map[int][]fpl.Fixture
+	elements map[int]fpl.Element
+	types    []fpl.ElementType
+}
+
+// getGameweek fetches a gameweek's live feed and fixtures. Past gameweeks
+// come from the store when they're in it and are saved to it when not; the
+// current one is saved by pollLive as it changes.
+func getGameweek(ctx context.Context, week int) (*gameweek, error) {
+	past := week < getCurrentGw()
+	if past {
+		if stored, ok := db.gameweek(week); ok {
+			return newGameweek(week, stored.Live, stored.Fixtures), nil
+		}
+	}
+	live, err := getLive(ctx, week)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := client.Fixtures(ctx, week)
+	if err != nil {
+		return nil, err
+	}
+	if past {
+		db.saveGameweek(week, live, fixtures)
+	} else if week == getCurrentGw() {
+		setInPlay(fixtures)
+	}
+	return newGameweek(week, live, fixtures), nil
+}
+
+// inPlay is 1 while a fixture of the current gameweek has kicked off but not
+// finished, as of the last fixtures fetch. It starts at 1 so the live feed is
+// cached briefly until the fixtures are known.
+var inPlay int32 = 1
+
+// matchesInPlay tells the client whether the live feed is still changing.
+func matchesInPlay() bool {
+	return atomic.LoadInt32(&inPlay) == 1
+}
+
+func setInPlay(fixtures []fpl.Fixture) {
+	var v int32
+	for _, fixture := range fixtures {
+		if fixture.Started && !fixture.FinishedProvisional {
+			v = 1
+		}
+	}
+	atomic.StoreInt32(&inPlay, v)
+}
+
+func newGameweek(week int, live liveIndex, fixtures []fpl.Fixture) *gameweek {
+	data, elements := getElements()
+	gw := &gameweek{
+		Event:    week,
+		Live:     live,
+		Fixtures: fixtures,
+		Bonus:    getBonusPoints(fixtures),
+		byID:     make(map[int]fpl.Fixture),
+		byTeam:   make(map[int][]fpl.Fixture),
+		elements: elements,
+	}
+	if data != nil {
+		gw.types = data.ElementTypes
+	}
+	for _, fixture := range fixtures {
+		gw.byID[fixture.ID] = fixture
+		gw.byTeam[fixture.TeamH] = append(gw.byTeam[fixture.TeamH], fixture)
+		gw.byTeam[fixture.TeamA] = append(gw.byTeam[fixture.TeamA], fixture)
+	}
+	return gw
+}
+
+// element is a player from bootstrap-static.
+func (gw *gameweek) element(id int) (fpl.Element, bool) {
+	element, ok := gw.elements[id]
+	return element, ok
+}
+
+// playerName is a player's short name, empty if bootstrap-static doesn't
+// know them.
+func (gw *gameweek) playerName(id int) string {
+	return gw.elements[id].WebName
+}
+
+// minutes is how long a player has been on the pitch this gameweek.
+func (gw *gameweek) minutes(element int) int {
+	return gw.Live[element].Stats.Minutes
+}
+
+// fixtureFinished reports whether a fixture's result and bonus are official.
+func (gw *gameweek) fixtureFinished(id int) bool {
+	return gw.byID[id].Finished
+}
+
+// finished reports whether every fixture of the gameweek is Finished, so its
+// scores are final.
+func (gw *gameweek) finished() bool {
+	for _, fixture := range gw.Fixtures {
+		if !fixture.Finished {
+			return false
+		}
+	}
+	return len(gw.Fixtures) > 0
+}
+
+// blank reports whether a player's team has no fixture this gameweek.
+func (gw *gameweek) blank(element int) bool {
+	player, ok := gw.element(element)
+	return ok && len(gw.byTeam[player.Team]) == 0
+}
+
+// fixturesDone reports whether every fixture of a player's team this gameweek
+// has finished. A player whose team blanks has nothing left to play.
+func (gw *gameweek) fixturesDone(element int) bool {
+	player, ok := gw.element(element)
+	if !ok {
+		return false
+	}
+	for _, fixture := range gw.byTeam[player.Team] {
+		if !fixture.Finished && !fixture.FinishedProvisional {
+			return false
+		}
+	}
+	return true
+}
+
+// playerCounts splits the players who score for a manager, those with a
+// non-zero multiplier, into those whose fixtures are all over, those on the
+// pitch right now and those with a match still to play. Multipliers already
+// reflect auto-subs and Bench Boost. A player counts as on the pitch once the
+// live feed has them playing minutes in a fixture that's in progress, so an
+// unused substitute is still to play; a double gameweek player stays "to
+// play" until they come on in their second match, and a player whose team
+// blanks has nothing left to play.
+func (gw *gameweek) playerCounts(multipliers map[int]int) (played, playing, toPlay int) {
+	for id, multiplier := range multipliers {
+		if multiplier == 0 {
+			continue
+		}
+		player, ok := gw.element(id)
+		if !ok {
+			continue
+		}
+		done, live := true, false
+		for _, fixture := range gw.byTeam[player.Team] {
+			if fixture.Finished || fixture.FinishedProvisional {
+				continue
+			}
+			done = false
+			if fixture.Started && gw.fixtureMinutes(id, fixture.ID) > 0 {
+				live = true
+			}
+		}
+		switch {
+		case done:
+			played++
+		case live:
+			playing++
+		default:
+			toPlay++
+		}
+	}
+	return played, playing, toPlay
+}
+
+// fixtureMinutes is how long a player has been on the pitch in one fixture,
+// from the live feed's per-fixture breakdown.
+func (gw *gameweek) fixtureMinutes(element, fixture int) int {
+	for _, explain := range gw.Live[element].Explain {
+		if explain.Fixture != fixture {
+			continue
+		}
+		for _, stat := range explain.Stats {
+			if stat.Identifier == "minutes" {
+				return stat.Value
+			}
+		}
+	}
+	return 0
+}
+
+// didNotPlay reports whether a player is certain to score nothing: all of
+// their fixtures are over and they never came on.
+func (gw *gameweek) didNotPlay(element int) bool {
+	return gw.minutes(element) == 0 && gw.fixturesDone(element)
+}
@@ -2,4 +2,10 @@ module github.com/lewislebentz/Go-FPL
 
 go 1.17
 
-require github.com/gorilla/mux v1.8.0
+require (
+	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/websocket v1.5.0
+	go.etcd.io/bbolt v1.3.7
+)
+
+require golang.org/x/sys v0.7.0 // indirect
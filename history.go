@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// historyChartLines caps how many managers are drawn on the rank chart; past
+// that the lines are unreadable. The table still lists everyone.
+const historyChartLines = 20
+
+// leagueHistory is a league's season so far, gameweek by gameweek, built
+// from each member's entry history.
+type leagueHistory struct {
+	League     int
+	Gameweeks  []int
+	Managers   []managerHistory // by league rank after the latest gameweek
+	Chart      rankChart
+	Truncated  bool
+	MaxEntries int
+}
+
+type managerHistory struct {
+	Entry    int
+	TeamName string
+	Rank     int           // after the latest gameweek
+	Weeks    []historyWeek // one per leagueHistory.Gameweeks
+}
+
+type historyWeek struct {
+	Played bool // false before the manager's first gameweek
+	Points int  // net of hits
+	Total  int
+	Rank   int // within the league, after this gameweek
+}
+
+// getLeagueHistory fetches every member's entry history, with the same worker
+// pool as getLeague, and ranks the league after each gameweek by total
+// points. Managers level on points share a rank.
+func getLeagueHistory(ctx context.Context, id int) (leagueHistory, error) {
+	standings, truncated, err := getStandings(ctx, id)
+	if err != nil {
+		return leagueHistory{}, err
+	}
+
+	histories := make([]*fpl.EntryHistory, len(standings))
+	err = forEachEntry(ctx, standings, func(ctx context.Context, i int) error {
+		history, err := client.EntryHistory(ctx, standings[i].Entry)
+		if err != nil {
+			return err
+		}
+		histories[i] = history
+		return nil
+	})
+	if err != nil {
+		return leagueHistory{}, err
+	}
+
+	return newLeagueHistory(id, standings, histories, truncated), nil
+}
+
+func newLeagueHistory(id int, standings []fpl.Standing, histories []*fpl.EntryHistory, truncated bool) leagueHistory {
+	last := 0
+	for _, history := range histories {
+		for _, week := range history.Current {
+			if week.Event > last {
+				last = week.Event
+			}
+		}
+	}
+	first := last
+	for _, history := range histories {
+		for _, week := range history.Current {
+			if week.Event < first {
+				first = week.Event
+			}
+		}
+	}
+
+	lh := leagueHistory{League: id, Truncated: truncated, MaxEntries: maxLeaguePages * 50}
+	for gw := first; gw <= last && gw > 0; gw++ {
+		lh.Gameweeks = append(lh.Gameweeks, gw)
+	}
+
+	for i, history := range histories {
+		byEvent := make(map[int]fpl.EventHistory, len(history.Current))
+		for _, week := range history.Current {
+			byEvent[week.Event] = week
+		}
+		m := managerHistory{
+			Entry:    standings[i].Entry,
+			TeamName: standings[i].EntryName,
+			Weeks:    make([]historyWeek, len(lh.Gameweeks)),
+		}
+		total := 0
+		for j, gw := range lh.Gameweeks {
+			if week, ok := byEvent[gw]; ok {
+				total = week.TotalPoints
+				m.Weeks[j] = historyWeek{Played: true, Points: week.Points - week.EventTransfersCost, Total: total}
+			} else {
+				m.Weeks[j].Total = total
+			}
+		}
+		lh.Managers = append(lh.Managers, m)
+	}
+
+	for j := range lh.Gameweeks {
+		order := make([]int, len(lh.Managers))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return lh.Managers[order[a]].Weeks[j].Total > lh.Managers[order[b]].Weeks[j].Total
+		})
+		for pos, i := range order {
+			rank := pos + 1
+			if pos > 0 {
+				prev := lh.Managers[order[pos-1]].Weeks[j]
+				if prev.Total == lh.Managers[i].Weeks[j].Total {
+					rank = prev.Rank
+				}
+			}
+			lh.Managers[i].Weeks[j].Rank = rank
+		}
+	}
+
+	if n := len(lh.Gameweeks); n > 0 {
+		for i := range lh.Managers {
+			lh.Managers[i].Rank = lh.Managers[i].Weeks[n-1].Rank
+		}
+		sort.SliceStable(lh.Managers, func(a, b int) bool {
+			return lh.Managers[a].Rank < lh.Managers[b].Rank
+		})
+	}
+	lh.Chart = newRankChart(lh)
+	return lh
+}
+
+// rankChart is the SVG line chart of league rank by gameweek, laid out here
+// so the template only has to draw it.
+type rankChart struct {
+	Width, Height int
+	Lines         []chartLine
+	XTicks        []chartTick
+	YTicks        []chartTick
+	Left, Right   int
+	Top, Bottom   int
+	LegendX       int
+	Omitted       int // managers left off to keep the chart readable
+}
+
+type chartLine struct {
+	Name    string
+	Colour  string
+	Points  string // SVG polyline points
+	LegendY int
+}
+
+type chartTick struct {
+	X, Y  int
+	Label string
+}
+
+var chartColours = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+func newRankChart(lh leagueHistory) rankChart {
+	c := rankChart{Width: 900, Height: 420, Left: 40, Right: 700, Top: 20, Bottom: 390, LegendX: 720}
+	n := len(lh.Gameweeks)
+	ranks := len(lh.Managers)
+	if n == 0 || ranks == 0 {
+		return c
+	}
+
+	x := func(j int) int {
+		if n == 1 {
+			return (c.Left + c.Right) / 2
+		}
+		return c.Left + j*(c.Right-c.Left)/(n-1)
+	}
+	y := func(rank int) int {
+		if ranks == 1 {
+			return (c.Top + c.Bottom) / 2
+		}
+		return c.Top + (rank-1)*(c.Bottom-c.Top)/(ranks-1)
+	}
+
+	// Label every gameweek while they fit, otherwise every few.
+	step := (n + 18) / 19
+	for j, gw := range lh.Gameweeks {
+		if j%step == 0 || j == n-1 {
+			c.XTicks = append(c.XTicks, chartTick{X: x(j), Y: c.Bottom + 20, Label: fmt.Sprint(gw)})
+		}
+	}
+	step = (ranks + 9) / 10
+	for rank := 1; rank <= ranks; rank += step {
+		c.YTicks = append(c.YTicks, chartTick{X: c.Left - 8, Y: y(rank), Label: fmt.Sprint(rank)})
+	}
+	if (ranks-1)%step != 0 {
+		c.YTicks = append(c.YTicks, chartTick{X: c.Left - 8, Y: y(ranks), Label: fmt.Sprint(ranks)})
+	}
+
+	managers := lh.Managers
+	if len(managers) > historyChartLines {
+		c.Omitted = len(managers) - historyChartLines
+		managers = managers[:historyChartLines]
+	}
+	for i, m := range managers {
+		var points []string
+		for j, week := range m.Weeks {
+			if week.Played {
+				points = append(points, fmt.Sprintf("%d,%d", x(j), y(week.Rank)))
+			}
+		}
+		c.Lines = append(c.Lines, chartLine{
+			Name:    m.TeamName,
+			Colour:  chartColours[i%len(chartColours)],
+			Points:  strings.Join(points, " "),
+			LegendY: c.Top + i*18,
+		})
+	}
+	return c
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// history builds an entry history from (event, points, total) triples.
+func history(weeks ...[3]int) *fpl.EntryHistory {
+	h := &fpl.EntryHistory{}
+	for _, w := range weeks {
+		h.Current = append(h.Current, fpl.EventHistory{Event: w[0], Points: w[1], TotalPoints: w[2]})
+	}
+	return h
+}
+
+func TestNewLeagueHistory(t *testing.T) {
+	standings := []fpl.Standing{{Entry: 1, EntryName: "A"}, {Entry: 2, EntryName: "B"}, {Entry: 3, EntryName: "C"}}
+	played := func(points, total, rank int) historyWeek {
+		return historyWeek{Played: true, Points: points, Total: total, Rank: rank}
+	}
+	tests := []struct {
+		name      string
+		histories []*fpl.EntryHistory
+		gameweeks []int
+		want      []managerHistory
+	}{
+		{
+			name: "level totals share a rank",
+			histories: []*fpl.EntryHistory{
+				history([3]int{1, 50, 50}, [3]int{2, 60, 110}),
+				history([3]int{1, 50, 50}, [3]int{2, 60, 110}),
+				history([3]int{1, 70, 70}, [3]int{2, 20, 90}),
+			},
+			gameweeks: []int{1, 2},
+			want: []managerHistory{
+				{1, "A", 1, []historyWeek{played(50, 50, 2), played(60, 110, 1)}},
+				{2, "B", 1, []historyWeek{played(50, 50, 2), played(60, 110, 1)}},
+				{3, "C", 3, []historyWeek{played(70, 70, 1), played(20, 90, 3)}},
+			},
+		},
+		{
+			name: "late joiner carries a zero total until their first gameweek",
+			histories: []*fpl.EntryHistory{
+				history([3]int{1, 50, 50}, [3]int{2, 40, 90}, [3]int{3, 30, 120}),
+				history([3]int{3, 130, 130}),
+				history([3]int{2, 60, 60}, [3]int{3, 10, 70}),
+			},
+			gameweeks: []int{1, 2, 3},
+			want: []managerHistory{
+				{2, "B", 1, []historyWeek{{Rank: 2}, {Rank: 3}, played(130, 130, 1)}},
+				{1, "A", 2, []historyWeek{played(50, 50, 1), played(40, 90, 1), played(30, 120, 2)}},
+				{3, "C", 3, []historyWeek{{Rank: 2}, played(60, 60, 2), played(10, 70, 3)}},
+			},
+		},
+		{
+			name: "gaps keep the last total",
+			histories: []*fpl.EntryHistory{
+				history([3]int{2, 50, 50}, [3]int{5, 60, 110}),
+				history([3]int{2, 70, 70}, [3]int{3, 10, 80}),
+				history([3]int{2, 40, 40}, [3]int{4, 45, 85}, [3]int{5, 0, 85}),
+			},
+			gameweeks: []int{2, 3, 4, 5},
+			want: []managerHistory{
+				{1, "A", 1, []historyWeek{played(50, 50, 2), {Total: 50, Rank: 2}, {Total: 50, Rank: 3}, played(60, 110, 1)}},
+				{3, "C", 2, []historyWeek{played(40, 40, 3), {Total: 40, Rank: 3}, played(45, 85, 1), played(0, 85, 2)}},
+				{2, "B", 3, []historyWeek{played(70, 70, 1), played(10, 80, 1), {Total: 80, Rank: 2}, {Total: 80, Rank: 3}}},
+			},
+		},
+		{
+			name:      "no gameweeks yet",
+			histories: []*fpl.EntryHistory{history(), history(), history()},
+			want: []managerHistory{
+				{1, "A", 0, []historyWeek{}},
+				{2, "B", 0, []historyWeek{}},
+				{3, "C", 0, []historyWeek{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lh := newLeagueHistory(1, standings, tt.histories, false)
+			if !reflect.DeepEqual(lh.Gameweeks, tt.gameweeks) {
+				t.Errorf("Gameweeks = %v, want %v", lh.Gameweeks, tt.gameweeks)
+			}
+			if !reflect.DeepEqual(lh.Managers, tt.want) {
+				t.Errorf("Managers = %+v, want %+v", lh.Managers, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLeagueHistoryHits(t *testing.T) {
+	h := &fpl.EntryHistory{Current: []fpl.EventHistory{{Event: 1, Points: 64, TotalPoints: 60, EventTransfersCost: 4}}}
+	lh := newLeagueHistory(1, []fpl.Standing{{Entry: 1}}, []*fpl.EntryHistory{h}, false)
+	if got := lh.Managers[0].Weeks[0]; got.Points != 60 || got.Total != 60 {
+		t.Errorf("week = %+v, want 60 points net of the hit", got)
+	}
+}
+
+// flatLeague is a league of managers over gameweeks 1 to weeks, manager i
+// always ranked i+1.
+func flatLeague(managers, weeks int) leagueHistory {
+	standings := make([]fpl.Standing, managers)
+	histories := make([]*fpl.EntryHistory, managers)
+	for i := range standings {
+		standings[i] = fpl.Standing{Entry: i + 1, EntryName: fmt.Sprint("Team ", i+1)}
+		histories[i] = &fpl.EntryHistory{}
+		for gw := 1; gw <= weeks; gw++ {
+			points := 100 - i
+			histories[i].Current = append(histories[i].Current, fpl.EventHistory{Event: gw, Points: points, TotalPoints: gw * points})
+		}
+	}
+	return newLeagueHistory(1, standings, histories, false)
+}
+
+func tickLabels(ticks []chartTick) string {
+	var labels []string
+	for _, tick := range ticks {
+		labels = append(labels, tick.Label)
+	}
+	return strings.Join(labels, " ")
+}
+
+func TestNewRankChart(t *testing.T) {
+	tests := []struct {
+		name             string
+		lh               leagueHistory
+		xTicks, yTicks   string
+		lines, omitted   int
+		firstLinePoints  string
+		secondLineLegend int
+	}{
+		{
+			name:            "one manager, one gameweek is centred",
+			lh:              flatLeague(1, 1),
+			xTicks:          "1",
+			yTicks:          "1",
+			lines:           1,
+			firstLinePoints: "370,205",
+		},
+		{
+			name:             "every rank labelled up to ten",
+			lh:               flatLeague(3, 3),
+			xTicks:           "1 2 3",
+			yTicks:           "1 2 3",
+			lines:            3,
+			firstLinePoints:  "40,20 370,20 700,20",
+			secondLineLegend: 38,
+		},
+		{
+			name:             "last rank labelled when the step misses it",
+			lh:               flatLeague(12, 2),
+			xTicks:           "1 2",
+			yTicks:           "1 3 5 7 9 11 12",
+			lines:            12,
+			firstLinePoints:  "40,20 700,20",
+			secondLineLegend: 38,
+		},
+		{
+			name:             "lines capped, gameweeks thinned",
+			lh:               flatLeague(25, 38),
+			xTicks:           "1 3 5 7 9 11 13 15 17 19 21 23 25 27 29 31 33 35 37 38",
+			yTicks:           "1 4 7 10 13 16 19 22 25",
+			lines:            historyChartLines,
+			omitted:          5,
+			secondLineLegend: 38,
+		},
+		{
+			name: "empty league",
+			lh:   flatLeague(0, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.lh.Chart
+			if got := tickLabels(c.XTicks); got != tt.xTicks {
+				t.Errorf("x ticks = %q, want %q", got, tt.xTicks)
+			}
+			if got := tickLabels(c.YTicks); got != tt.yTicks {
+				t.Errorf("y ticks = %q, want %q", got, tt.yTicks)
+			}
+			if len(c.Lines) != tt.lines || c.Omitted != tt.omitted {
+				t.Errorf("%v lines, %v omitted; want %v, %v", len(c.Lines), c.Omitted, tt.lines, tt.omitted)
+			}
+			if tt.firstLinePoints != "" && c.Lines[0].Points != tt.firstLinePoints {
+				t.Errorf("first line = %q, want %q", c.Lines[0].Points, tt.firstLinePoints)
+			}
+			if tt.secondLineLegend != 0 && c.Lines[1].LegendY != tt.secondLineLegend {
+				t.Errorf("second legend at %v, want %v", c.Lines[1].LegendY, tt.secondLineLegend)
+			}
+		})
+	}
+}
+
+func TestNewRankChartSkipsUnplayedWeeks(t *testing.T) {
+	lh := newLeagueHistory(1,
+		[]fpl.Standing{{Entry: 1}, {Entry: 2}},
+		[]*fpl.EntryHistory{
+			history([3]int{1, 50, 50}, [3]int{2, 50, 100}, [3]int{3, 50, 150}),
+			history([3]int{3, 200, 200}),
+		}, false)
+	// Entry 2 joined in gameweek 3 and tops the league; its line is a
+	// single point.
+	if got := lh.Chart.Lines[0].Points; got != "700,20" {
+		t.Errorf("late joiner's line = %q, want a single point", got)
+	}
+	if got := lh.Chart.Lines[1].Points; got != "40,20 370,20 700,390" {
+		t.Errorf("first manager's line = %q", got)
+	}
+}
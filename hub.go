@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// hubScoreTimeout bounds scoring one league or manager for a live update.
+	hubScoreTimeout = time.Minute
+	// hubConcurrency caps how many leagues and managers a hub scores at once.
+	// Each league is scored on its own pool of leagueConcurrency workers.
+	hubConcurrency = 4
+)
+
+// follower is a live connection an updateHub sends updates to.
+type follower interface {
+	// follows is the leagues and managers the follower wants updates for.
+	follows() (leagues, managers []int)
+	// deliver queues an encoded update without blocking.
+	deliver(msg []byte)
+}
+
+// updateHub scores each league and manager its followers want once per live
+// update and hands the encoded result to every follower of it. It only
+// listens to liveUpdates while it has followers. league and manager encode
+// one update; a nil manager means followers only follow leagues.
+type updateHub struct {
+	league  func(ctx context.Context, id int, gw *gameweek) []byte
+	manager func(ctx context.Context, id int, gw *gameweek) []byte
+
+	mu        sync.Mutex
+	followers map[follower]struct{}
+	stop      chan struct{}
+}
+
+func newUpdateHub(league, manager func(ctx context.Context, id int, gw *gameweek) []byte) *updateHub {
+	return &updateHub{league: league, manager: manager, followers: make(map[follower]struct{})}
+}
+
+func (h *updateHub) register(f follower) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.followers[f] = struct{}{}
+	if len(h.followers) == 1 {
+		h.stop = make(chan struct{})
+		go h.fanOut(liveUpdates.subscribe(), h.stop)
+	}
+}
+
+func (h *updateHub) unregister(f follower) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.followers[f]; !ok {
+		return
+	}
+	delete(h.followers, f)
+	if len(h.followers) == 0 {
+		close(h.stop)
+	}
+}
+
+// size is how many followers the hub has.
+func (h *updateHub) size() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.followers)
+}
+
+// fanOut sends every follower its leagues and managers each time the
+// gameweek changes, until stop is closed.
+func (h *updateHub) fanOut(updates chan *gameweek, stop chan struct{}) {
+	defer liveUpdates.unsubscribe(updates)
+	for {
+		select {
+		case gw := <-updates:
+			h.broadcast(gw)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// hubJob is one league or manager to score and who to send it to.
+type hubJob struct {
+	id        int
+	encode    func(ctx context.Context, id int, gw *gameweek) []byte
+	followers []follower
+}
+
+func (h *updateHub) broadcast(gw *gameweek) {
+	h.mu.Lock()
+	followers := make([]follower, 0, len(h.followers))
+	for f := range h.followers {
+		followers = append(followers, f)
+	}
+	h.mu.Unlock()
+
+	leagues := make(map[int][]follower)
+	managers := make(map[int][]follower)
+	for _, f := range followers {
+		l, m := f.follows()
+		for _, id := range l {
+			leagues[id] = append(leagues[id], f)
+		}
+		for _, id := range m {
+			managers[id] = append(managers[id], f)
+		}
+	}
+	var jobs []hubJob
+	for id, fs := range leagues {
+		jobs = append(jobs, hubJob{id, h.league, fs})
+	}
+	if h.manager != nil {
+		for id, fs := range managers {
+			jobs = append(jobs, hubJob{id, h.manager, fs})
+		}
+	}
+	scoreJobs(context.Background(), gw, jobs)
+}
+
+// scoreJobs encodes and delivers jobs on a pool of hubConcurrency workers.
+// Each job gets its own hubScoreTimeout within ctx, so one slow league
+// doesn't starve the rest of the round. A job that encodes to nil is not
+// delivered.
+func scoreJobs(ctx context.Context, gw *gameweek, jobs []hubJob) {
+	queue := make(chan hubJob)
+	var wg sync.WaitGroup
+	for w := 0; w < hubConcurrency && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				jobCtx, cancel := context.WithTimeout(ctx, hubScoreTimeout)
+				msg := job.encode(jobCtx, job.id, gw)
+				cancel()
+				if msg == nil {
+					continue
+				}
+				for _, f := range job.followers {
+					f.deliver(msg)
+				}
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// leagueConcurrency caps how many managers are scored at once, set with
+// LEAGUE_CONCURRENCY.
+var leagueConcurrency = 8
+
+// maxLeaguePages caps how many 50-entry pages of a league are fetched, set
+// with MAX_LEAGUE_PAGES. 0 means no cap.
+var maxLeaguePages = 20
+
+// getLeague scores every entry of a classic league against the gameweek's
+// live feed and ranks them by live total. truncated reports whether the
+// league has more entries than maxLeaguePages let us fetch.
+func getLeague(ctx context.Context, id int, gw *gameweek) (rows []row, truncated bool, err error) {
+	standings, truncated, err := getStandings(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rows = make([]row, len(standings))
+	err = forEachEntry(ctx, standings, func(ctx context.Context, i int) error {
+		result, err := scoreEntry(ctx, standings[i], gw)
+		if err != nil {
+			return err
+		}
+		rows[i] = result
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Ties keep the official league order so a refresh never reshuffles them.
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].LiveTotal != rows[j].LiveTotal {
+			return rows[i].LiveTotal > rows[j].LiveTotal
+		}
+		return rows[i].Rank < rows[j].Rank
+	})
+	for i := range rows {
+		rows[i].Rank = i + 1
+	}
+	db.saveLeague(id, gw.Event, rows, truncated, gw.finished())
+	return rows, truncated, nil
+}
+
+// rebuildLeague scores a past gameweek we never saw live for today's league
+// members. Members with no picks that week, having joined FPL since, are left
+// out. Today's standings say nothing about that week's ranks, so LastRank is
+// left empty and ties go by entry ID. The table isn't a record of what the
+// league looked like, so it isn't saved.
+func rebuildLeague(ctx context.Context, id int, gw *gameweek) ([]row, bool, error) {
+	standings, truncated, err := getStandings(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	scored := make([]row, len(standings))
+	found := make([]bool, len(standings))
+	err = forEachEntry(ctx, standings, func(ctx context.Context, i int) error {
+		standing := fpl.Standing{Entry: standings[i].Entry, EntryName: standings[i].EntryName}
+		result, err := scoreEntry(ctx, standing, gw)
+		if errors.Is(err, fpl.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		scored[i], found[i] = result, true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var rows []row
+	for i, result := range scored {
+		if found[i] {
+			rows = append(rows, result)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].LiveTotal != rows[j].LiveTotal {
+			return rows[i].LiveTotal > rows[j].LiveTotal
+		}
+		return rows[i].TeamID < rows[j].TeamID
+	})
+	for i := range rows {
+		rows[i].Rank = i + 1
+	}
+	return rows, truncated, nil
+}
+
+// forEachEntry calls fn for every index of standings on a pool of
+// leagueConcurrency workers. The first error cancels the ctx passed to the
+// rest and is returned; cancelling ctx (the browser disconnecting) stops the
+// pool and returns ctx's error.
+func forEachEntry(ctx context.Context, standings []fpl.Standing, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for w := 0; w < leagueConcurrency && w < len(standings); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(ctx, i); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range standings {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+	}
+	return ctx.Err()
+}
+
+// getStandings fetches a league's standings page by page until the last page
+// or maxLeaguePages, reporting whether it stopped short.
+func getStandings(ctx context.Context, id int) ([]fpl.Standing, bool, error) {
+	var standings []fpl.Standing
+	for page := 1; ; page++ {
+		if maxLeaguePages > 0 && page > maxLeaguePages {
+			return standings, true, nil
+		}
+		responseObject, err := client.ClassicStandings(ctx, id, page, 1)
+		if err != nil {
+			return nil, false, err
+		}
+		standings = append(standings, responseObject.Standings.Results...)
+		if !responseObject.Standings.HasNext {
+			return standings, false, nil
+		}
+	}
+}
+
+func scoreEntry(ctx context.Context, element fpl.Standing, gw *gameweek) (row, error) {
+	snap, err := getSnapshot(ctx, element.Entry, gw.Event)
+	if err != nil {
+		return row{}, err
+	}
+	multipliers, subs := snap.multipliers(gw)
+	captainID := snap.effectiveCaptain(gw)
+	// Hits come off the gameweek total, as they do on the FPL site.
+	eventTotal := getLiveScore(gw, multipliers) - snap.TransfersCost
+	liveTotal := eventTotal + snap.PrevTotal
+	captain := "N/A"
+	if captainID != 0 {
+		captain = gw.playerName(captainID)
+	}
+	if captainID != snap.Captain {
+		captain += " (VC)"
+	}
+	played, playing, toPlay := gw.playerCounts(multipliers)
+	var subNames []string
+	for _, sub := range subs {
+		subNames = append(subNames, gw.playerName(sub.Out)+" → "+gw.playerName(sub.In))
+	}
+	var blanks []string
+	for _, id := range snap.Starting {
+		if gw.blank(id) {
+			blanks = append(blanks, gw.playerName(id))
+		}
+	}
+	return row{element.RankSort, element.Entry, element.EntryName, eventTotal, liveTotal, snap.PrevTotal, element.LastRank, snap.BenchPts, captain, played, playing, toPlay, subNames, blanks, snap.chipName(), snap.Transfers, snap.TransfersCost}, nil
+}
+
+// getManagerLive scores one manager against the gameweek outside of any
+// league, so the row's ranks are left at zero.
+func getManagerLive(ctx context.Context, id int, gw *gameweek) (row, error) {
+	entry, err := client.Entry(ctx, id)
+	if err != nil {
+		return row{}, err
+	}
+	return scoreEntry(ctx, fpl.Standing{Entry: id, EntryName: entry.Name}, gw)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// newLeagueServer mocks the API for a league of entries managers.
+func newLeagueServer(t *testing.T, entries int) *httptest.Server {
+	server := httptest.NewServer(leagueAPI(entries))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// leagueAPI serves the standings and picks of a league of entries managers,
+// entry e having 10*e points before the gameweek, and an empty live feed and
+// fixture list.
+func leagueAPI(entries int) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/leagues-classic/1/standings/", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page_standings"))
+		var v fpl.ClassicStandings
+		for e := (page-1)*50 + 1; e <= page*50 && e <= entries; e++ {
+			v.Standings.Results = append(v.Standings.Results, fpl.Standing{Entry: e, EntryName: fmt.Sprint("Team ", e), RankSort: e})
+		}
+		v.Standings.HasNext = page*50 < entries
+		json.NewEncoder(w).Encode(v)
+	})
+	mux.HandleFunc("/entry/", func(w http.ResponseWriter, r *http.Request) {
+		var e, week int
+		if _, err := fmt.Sscanf(r.URL.Path, "/entry/%d/event/%d/picks/", &e, &week); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		v := fpl.Picks{EntryHistory: fpl.EventHistory{Event: week, TotalPoints: 10 * e}}
+		for pos := 1; pos <= 15; pos++ {
+			v.Picks = append(v.Picks, fpl.Pick{Element: pos, Position: pos, Multiplier: 1, IsCaptain: pos == 6})
+		}
+		json.NewEncoder(w).Encode(v)
+	})
+	mux.HandleFunc("/event/", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(fpl.Live{})
+	})
+	mux.HandleFunc("/fixtures/", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]fpl.Fixture{})
+	})
+	return mux
+}
+
+func TestGetLeagueConcurrent(t *testing.T) {
+	setTestBootstrap()
+	server := newLeagueServer(t, 120)
+	saved := client
+	client = fpl.NewClient(server.URL, nil)
+	client.Limiter = nil
+	t.Cleanup(func() { client = saved })
+	gw := testGameweek(true, nil, nil)
+
+	var wg sync.WaitGroup
+	results := make([][]row, 8)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, errs[i] = getLeague(context.Background(), 1, gw)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, rows := range results {
+		if errs[i] != nil {
+			t.Fatalf("getLeague() error = %v", errs[i])
+		}
+		if len(rows) != 120 {
+			t.Fatalf("getLeague() returned %v rows, want 120", len(rows))
+		}
+		for j, r := range rows {
+			if r.Rank != j+1 || r.TeamID != 120-j {
+				t.Fatalf("row %v = rank %v entry %v, want rank %v entry %v", j, r.Rank, r.TeamID, j+1, 120-j)
+			}
+		}
+		if !reflect.DeepEqual(rows, results[0]) {
+			t.Errorf("getLeague() call %v differs from call 0", i)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// livePollInterval is how often the live feed is polled while a fixture is
+// in progress, set with LIVE_POLL.
+var livePollInterval = 30 * time.Second
+
+// liveIdleInterval is the longest the poller sleeps between match windows,
+// in case kickoffs move.
+var liveIdleInterval = time.Hour
+
+// kickoffGrace is how long after its kickoff time a fixture the feed hasn't
+// marked as started yet is still treated as about to start.
+const kickoffGrace = 3 * time.Hour
+
+// liveHub hands the latest gameweek to everyone streaming live updates. Each
+// subscriber has a one-slot channel that always holds the newest gameweek, so
+// a slow reader skips stale states instead of holding up the others.
+type liveHub struct {
+	mu   sync.Mutex
+	subs map[chan *gameweek]struct{}
+}
+
+var liveUpdates = &liveHub{subs: make(map[chan *gameweek]struct{})}
+
+func (h *liveHub) subscribe() chan *gameweek {
+	ch := make(chan *gameweek, 1)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *liveHub) unsubscribe(ch chan *gameweek) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+}
+
+func (h *liveHub) publish(gw *gameweek) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- gw
+	}
+}
+
+// pollLive keeps the current gameweek's live feed and fixtures fresh in the
+// client's cache, so page views read them from there, and saves and publishes
+// the gameweek whenever the live feed, fixtures or provisional bonus change.
+// Once every fixture is Finished it re-scores the leagues stored for the
+// gameweek, so their tables are the final result. It polls every
+// livePollInterval during matches and sleeps until the next kickoff otherwise.
+func pollLive(ctx context.Context) {
+	var last uint64
+	var finalised int
+	for {
+		wait := livePollInterval
+		gw, err := getGameweek(fpl.NoCache(ctx), getCurrentGw())
+		if err != nil {
+			log.Printf("polling live feed: %v", err)
+		} else {
+			if sum := gw.checksum(); sum != last {
+				last = sum
+				db.saveGameweek(gw.Event, gw.Live, gw.Fixtures)
+				liveUpdates.publish(gw)
+			}
+			if gw.finished() && finalised != gw.Event {
+				finalised = gw.Event
+				go finaliseLeagues(ctx, gw)
+			}
+			wait = nextPoll(gw.Fixtures, time.Now())
+			if wait > livePollInterval {
+				log.Printf("no matches in progress, next live poll in %v", wait.Round(time.Second))
+			}
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
+
+// finaliseLeagues re-scores and saves every league stored for a finished
+// gameweek.
+func finaliseLeagues(ctx context.Context, gw *gameweek) {
+	for _, id := range db.leagues(gw.Event) {
+		if _, _, err := getLeague(ctx, id, gw); err != nil {
+			log.Printf("finalising league %v: %v", id, err)
+		}
+	}
+}
+
+// nextPoll is how long to wait before polling the live feed again:
+// livePollInterval while any fixture is in progress, otherwise until the next
+// kickoff, just after the next deadline (when the gameweek rolls over) or
+// liveIdleInterval, whichever comes first.
+func nextPoll(fixtures []fpl.Fixture, now time.Time) time.Duration {
+	next := now.Add(liveIdleInterval)
+	for _, fixture := range fixtures {
+		if fixture.Finished {
+			continue
+		}
+		if fixture.Started {
+			return livePollInterval
+		}
+		kickoff := fixture.KickoffTime
+		if kickoff.IsZero() {
+			// Postponed, no date yet.
+			continue
+		}
+		if !kickoff.After(now) {
+			if now.Sub(kickoff) < kickoffGrace {
+				return livePollInterval
+			}
+			continue
+		}
+		if kickoff.Before(next) {
+			next = kickoff
+		}
+	}
+	if deadline := nextDeadline(getBootstrap()); !deadline.IsZero() && deadline.After(now) {
+		// Give refreshBootstrap a moment to roll the gameweek over first.
+		if rollover := deadline.Add(2 * time.Minute); rollover.Before(next) {
+			next = rollover
+		}
+	}
+	if wait := next.Sub(now); wait > livePollInterval {
+		return wait
+	}
+	return livePollInterval
+}
+
+// checksum fingerprints everything a manager's score is worked out from.
+func (gw *gameweek) checksum() uint64 {
+	h := fnv.New64a()
+	enc := json.NewEncoder(h)
+	enc.Encode(gw.Event)
+	enc.Encode(gw.Live)
+	enc.Encode(gw.Fixtures)
+	enc.Encode(gw.Bonus)
+	return h.Sum64()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+func TestNextPoll(t *testing.T) {
+	now := time.Date(2023, 9, 2, 15, 0, 0, 0, time.UTC)
+	finished := fpl.Fixture{Started: true, Finished: true, KickoffTime: now.Add(-3 * time.Hour)}
+	tests := []struct {
+		name     string
+		fixtures []fpl.Fixture
+		deadline time.Time
+		want     time.Duration
+	}{
+		{
+			name:     "match in progress",
+			fixtures: []fpl.Fixture{finished, {Started: true, KickoffTime: now.Add(-time.Hour)}},
+			want:     livePollInterval,
+		},
+		{
+			name:     "kicked off but not flagged started, within grace",
+			fixtures: []fpl.Fixture{{KickoffTime: now.Add(-10 * time.Minute)}},
+			want:     livePollInterval,
+		},
+		{
+			name:     "kicked off past grace",
+			fixtures: []fpl.Fixture{{KickoffTime: now.Add(-kickoffGrace - time.Minute)}},
+			want:     liveIdleInterval,
+		},
+		{
+			name:     "next kickoff",
+			fixtures: []fpl.Fixture{finished, {KickoffTime: now.Add(20 * time.Minute)}},
+			want:     20 * time.Minute,
+		},
+		{
+			name:     "kickoff sooner than the poll interval",
+			fixtures: []fpl.Fixture{{KickoffTime: now.Add(time.Second)}},
+			want:     livePollInterval,
+		},
+		{
+			name:     "postponed fixture with no kickoff",
+			fixtures: []fpl.Fixture{finished, {}},
+			want:     liveIdleInterval,
+		},
+		{
+			name:     "all finished",
+			fixtures: []fpl.Fixture{finished},
+			want:     liveIdleInterval,
+		},
+		{
+			name:     "deadline caps the wait",
+			fixtures: []fpl.Fixture{finished},
+			deadline: now.Add(10 * time.Minute),
+			want:     12 * time.Minute,
+		},
+		{
+			name:     "kickoff before the deadline",
+			fixtures: []fpl.Fixture{{KickoffTime: now.Add(5 * time.Minute)}},
+			deadline: now.Add(10 * time.Minute),
+			want:     5 * time.Minute,
+		},
+		{
+			name:     "deadline passed",
+			fixtures: []fpl.Fixture{finished},
+			deadline: now.Add(-time.Minute),
+			want:     liveIdleInterval,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setBootstrap(&fpl.Bootstrap{Events: []fpl.Event{
+				{ID: 4, IsCurrent: true},
+				{ID: 5, IsNext: true, DeadlineTime: tt.deadline},
+			}})
+			if got := nextPoll(tt.fixtures, now); got != tt.want {
+				t.Errorf("nextPoll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+func TestAllocateBonus(t *testing.T) {
+	tests := []struct {
+		name string
+		bps  []bonusPointsCalc
+		want map[int]int
+	}{
+		{
+			name: "clear top three",
+			bps:  []bonusPointsCalc{{1, 30}, {2, 40}, {3, 20}, {4, 10}},
+			want: map[int]int{2: 3, 1: 2, 3: 1},
+		},
+		{
+			name: "two-way tie for first",
+			bps:  []bonusPointsCalc{{1, 40}, {2, 40}, {3, 30}, {4, 20}},
+			want: map[int]int{1: 3, 2: 3, 3: 1},
+		},
+		{
+			name: "three-way tie for first",
+			bps:  []bonusPointsCalc{{1, 40}, {2, 40}, {3, 40}, {4, 30}},
+			want: map[int]int{1: 3, 2: 3, 3: 3},
+		},
+		{
+			name: "tie for second",
+			bps:  []bonusPointsCalc{{1, 40}, {2, 30}, {3, 30}, {4, 20}},
+			want: map[int]int{1: 3, 2: 2, 3: 2},
+		},
+		{
+			name: "tie for third",
+			bps:  []bonusPointsCalc{{1, 40}, {2, 35}, {3, 30}, {4, 30}, {5, 30}, {6, 20}},
+			want: map[int]int{1: 3, 2: 2, 3: 1, 4: 1, 5: 1},
+		},
+		{
+			name: "players beyond third get nothing",
+			bps:  []bonusPointsCalc{{1, 50}, {2, 40}, {3, 30}, {4, 29}, {5, 28}},
+			want: map[int]int{1: 3, 2: 2, 3: 1},
+		},
+		{
+			name: "empty table",
+			bps:  nil,
+			want: map[int]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allocateBonus(tt.bps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateBonus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBonusPointsSkipsFinished(t *testing.T) {
+	var fixtures []fpl.Fixture
+	err := json.Unmarshal([]byte(`[
+		{"id": 1, "finished": true, "stats": [{"identifier": "bps", "h": [{"element": 1, "value": 40}], "a": [{"element": 2, "value": 30}]}]},
+		{"id": 2, "finished": false, "stats": [{"identifier": "bps", "h": [{"element": 3, "value": 40}], "a": [{"element": 4, "value": 30}]}]}
+	]`), &fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := provisionalBonus{2: {3: 3, 4: 2}}
+	if got := getBonusPoints(fixtures); !reflect.DeepEqual(got, want) {
+		t.Errorf("getBonusPoints() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// gameweekSnapshot is a manager's team and scores for one gameweek, built
+// from a single picks response so the league table needs one picks fetch per
+// manager.
+type gameweekSnapshot struct {
+	Entry         int
+	Event         int
+	Picks         []fpl.Pick
+	Starting      []int // starting XI element IDs, in team sheet order
+	Bench         []int // bench element IDs, in substitution order
+	Captain       int
+	ViceCaptain   int
+	Chip          string
+	Points        int
+	TotalPoints   int
+	PrevTotal     int // total points before this gameweek
+	BenchPts      int
+	Transfers     int
+	TransfersCost int
+	Bank          int
+	Value         int
+}
+
+// getSnapshot builds a manager's snapshot from their picks. Picks for past
+// gameweeks are read from the store when we have them, and saved when we
+// don't. The current gameweek's are always fetched and never saved: their
+// points and bench points are only a snapshot of the week so far.
+func getSnapshot(ctx context.Context, id, week int) (gameweekSnapshot, error) {
+	past := week < getCurrentGw()
+	if past {
+		if stored, ok := db.picks(id, week); ok {
+			return newSnapshot(id, stored), nil
+		}
+	}
+	responseObject, err := client.EntryPicks(ctx, id, week)
+	if err != nil {
+		return gameweekSnapshot{}, err
+	}
+	if past {
+		db.savePicks(id, week, responseObject)
+	}
+	return newSnapshot(id, responseObject), nil
+}
+
+func newSnapshot(id int, p *fpl.Picks) gameweekSnapshot {
+	history := p.EntryHistory
+	snap := gameweekSnapshot{
+		Entry:       id,
+		Event:       history.Event,
+		Picks:       p.Picks,
+		Chip:        p.ActiveChip,
+		Points:      history.Points,
+		TotalPoints: history.TotalPoints,
+		// total_points is already net of this week's hits, points isn't.
+		PrevTotal:     history.TotalPoints - history.Points + history.EventTransfersCost,
+		BenchPts:      history.PointsOnBench,
+		Transfers:     history.EventTransfers,
+		TransfersCost: history.EventTransfersCost,
+		Bank:          history.Bank,
+		Value:         history.Value,
+	}
+	for _, pick := range p.Picks {
+		if pick.Position <= 11 {
+			snap.Starting = append(snap.Starting, pick.Element)
+		} else {
+			snap.Bench = append(snap.Bench, pick.Element)
+		}
+		if pick.IsCaptain {
+			snap.Captain = pick.Element
+		}
+		if pick.IsViceCaptain {
+			snap.ViceCaptain = pick.Element
+		}
+	}
+	return snap
+}
+
+// effectiveCaptain is the player who gets the captain's multiplier: the
+// captain, or the vice-captain once the captain's fixtures are over without
+// them playing.
+func (s gameweekSnapshot) effectiveCaptain(gw *gameweek) int {
+	if s.Captain != 0 && gw.didNotPlay(s.Captain) && s.ViceCaptain != 0 {
+		return s.ViceCaptain
+	}
+	return s.Captain
+}
+
+// multipliers is what each pick's live points count for: the multipliers from
+// the picks payload (which already carry Triple Captain and Bench Boost), with
+// automatic substitutions and the vice-captain fallback applied on top. Bench
+// Boost scores all fifteen, so no one is substituted. It also returns the
+// substitutions made.
+func (s gameweekSnapshot) multipliers(gw *gameweek) (map[int]int, []substitution) {
+	m := make(map[int]int, len(s.Picks))
+	for _, pick := range s.Picks {
+		m[pick.Element] = pick.Multiplier
+	}
+
+	if captain := s.effectiveCaptain(gw); captain != s.Captain {
+		m[captain] = m[s.Captain]
+		m[s.Captain] = 1
+	}
+
+	var subs []substitution
+	if s.Chip != "bboost" {
+		_, subs = autoSubs(s, gw)
+		for _, sub := range subs {
+			m[sub.In] = 1
+			m[sub.Out] = 0
+		}
+	}
+	return m, subs
+}
+
+var chipNames = map[string]string{
+	"3xc":      "Triple Captain",
+	"bboost":   "Bench Boost",
+	"freehit":  "Free Hit",
+	"wildcard": "Wildcard",
+}
+
+// chipName is the display name of the chip played this gameweek, if any.
+func (s gameweekSnapshot) chipName() string {
+	if name, ok := chipNames[s.Chip]; ok {
+		return name
+	}
+	return s.Chip
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// sseKeepalive is how often an idle stream gets a comment, so proxies don't
+// close it between goals.
+const sseKeepalive = 30 * time.Second
+
+// sseStream is one browser following a league's standings. send holds the
+// newest event only, so a slow reader skips stale tables.
+type sseStream struct {
+	league int
+	send   chan []byte
+}
+
+// sseClients scores each followed league once per live update and hands the
+// encoded event to every stream following it.
+var sseClients = newUpdateHub(standingsEvent, nil)
+
+// serveEvents streams a league's standings as server-sent events: the current
+// table straight away, then a new one each time the gameweek changes.
+func serveEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["league"])
+	if err != nil {
+		renderJSONError(w, fmt.Errorf("league %q: %w", vars["league"], fpl.ErrNotFound))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	s := &sseStream{league: id, send: make(chan []byte, 1)}
+	sseClients.register(s)
+	defer sseClients.unregister(s)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	ctx, cancel := context.WithTimeout(r.Context(), hubScoreTimeout)
+	if gw, err := getGameweek(ctx, getCurrentGw()); err != nil {
+		log.Println(err)
+	} else if event := standingsEvent(ctx, id, gw); event != nil {
+		w.Write(event)
+	}
+	cancel()
+	flusher.Flush()
+
+	keepalive := time.NewTicker(sseKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case event := <-s.send:
+			w.Write(event)
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// follows is the stream's league.
+func (s *sseStream) follows() (leagues, managers []int) {
+	return []int{s.league}, nil
+}
+
+// deliver replaces any event the stream hasn't written yet with event.
+func (s *sseStream) deliver(event []byte) {
+	select {
+	case <-s.send:
+	default:
+	}
+	select {
+	case s.send <- event:
+	default:
+	}
+}
+
+// standingsEvent is the league's standings encoded as a "standings" event, or
+// nil if they couldn't be worked out.
+func standingsEvent(ctx context.Context, id int, gw *gameweek) []byte {
+	rows, truncated, err := getLeague(ctx, id, gw)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	data, err := json.Marshal(newAPILeague(id, gw.Event, rows, truncated))
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	return []byte(fmt.Sprintf("event: standings\ndata: %s\n\n", data))
+}
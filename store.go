@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lewislebentz/Go-FPL/fpl"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	leaguesBucket   = []byte("leagues")
+	gameweeksBucket = []byte("gameweeks")
+	picksBucket     = []byte("picks")
+)
+
+// store keeps every league table, live feed and picks we work out in a bbolt
+// file, keyed by league or manager and gameweek, so past gameweeks can be
+// shown as they were after FPL has moved on and after a restart. A nil
+// *store stores nothing and finds nothing.
+type store struct {
+	bolt *bolt.DB
+}
+
+// db is the app's store, at DB_PATH.
+var db *store
+
+type storedLeague struct {
+	Rows      []row
+	Truncated bool
+	// Final is set once the table was scored with every fixture Finished.
+	Final bool
+	// Updated is when the table last changed.
+	Updated time.Time
+}
+
+type storedGameweek struct {
+	Live     liveIndex
+	Fixtures []fpl.Fixture
+}
+
+func openStore(path string) (*store, error) {
+	b, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = b.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{leaguesBucket, gameweeksBucket, picksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	return &store{b}, nil
+}
+
+func storeKey(id, week int) []byte {
+	return []byte(fmt.Sprintf("%d/%d", id, week))
+}
+
+func (s *store) league(id, week int) (storedLeague, bool) {
+	var l storedLeague
+	ok := s.get(leaguesBucket, storeKey(id, week), &l)
+	return l, ok
+}
+
+// saveLeague stores a league's table for a gameweek unless it's the one
+// already stored, so Updated stays the time it last changed.
+func (s *store) saveLeague(id, week int, rows []row, truncated, final bool) {
+	if old, ok := s.league(id, week); ok && old.Truncated == truncated && old.Final == final {
+		was, _ := json.Marshal(old.Rows)
+		now, _ := json.Marshal(rows)
+		if bytes.Equal(was, now) {
+			return
+		}
+	}
+	s.put(leaguesBucket, storeKey(id, week), storedLeague{rows, truncated, final, time.Now()})
+}
+
+// leagues lists the leagues with a table stored for a gameweek.
+func (s *store) leagues(week int) []int {
+	if s == nil {
+		return nil
+	}
+	var ids []int
+	s.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(leaguesBucket).ForEach(func(k, _ []byte) error {
+			var id, w int
+			if _, err := fmt.Sscanf(string(k), "%d/%d", &id, &w); err == nil && w == week {
+				ids = append(ids, id)
+			}
+			return nil
+		})
+	})
+	return ids
+}
+
+func (s *store) gameweek(week int) (storedGameweek, bool) {
+	var gw storedGameweek
+	ok := s.get(gameweeksBucket, []byte(fmt.Sprint(week)), &gw)
+	return gw, ok
+}
+
+func (s *store) saveGameweek(week int, live liveIndex, fixtures []fpl.Fixture) {
+	s.put(gameweeksBucket, []byte(fmt.Sprint(week)), storedGameweek{live, fixtures})
+}
+
+func (s *store) picks(entry, week int) (*fpl.Picks, bool) {
+	var p fpl.Picks
+	ok := s.get(picksBucket, storeKey(entry, week), &p)
+	return &p, ok
+}
+
+func (s *store) savePicks(entry, week int, p *fpl.Picks) {
+	s.put(picksBucket, storeKey(entry, week), p)
+}
+
+// get decodes the value at key into v, reporting whether there was one.
+func (s *store) get(bucket, key []byte, v interface{}) bool {
+	if s == nil {
+		return false
+	}
+	var found bool
+	err := s.bolt.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get(key)
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, v)
+	})
+	if err != nil {
+		log.Printf("reading %s %s: %v", bucket, key, err)
+		return false
+	}
+	return found
+}
+
+// put stores v at key, skipping the write when it's already there so the
+// same table or picks seen on every page view cost a read, not a sync.
+// Failing to save only costs us history, so errors are logged rather than
+// returned.
+func (s *store) put(bucket, key []byte, v interface{}) {
+	if s == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		var unchanged bool
+		s.bolt.View(func(tx *bolt.Tx) error {
+			unchanged = bytes.Equal(tx.Bucket(bucket).Get(key), data)
+			return nil
+		})
+		if unchanged {
+			return
+		}
+		err = s.bolt.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(bucket).Put(key, data)
+		})
+	}
+	if err != nil {
+		log.Printf("saving %s %s: %v", bucket, key, err)
+	}
+}
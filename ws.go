@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsSendBuffer is how many messages a connection may fall behind by
+	// before it is dropped.
+	wsSendBuffer = 16
+	// wsPendingSubscribes is how many subscribes a connection may have
+	// waiting to be answered.
+	wsPendingSubscribes = 4
+	wsWriteWait         = 10 * time.Second
+	// wsMaxMessage caps the size of a client request.
+	wsMaxMessage = 4096
+	// wsMaxSubscriptions caps how many leagues and managers, together, one
+	// connection may follow.
+	wsMaxSubscriptions = 20
+)
+
+var (
+	wsPingPeriod = 30 * time.Second
+	// wsPongWait is how long a connection may go without answering a ping
+	// before it is closed.
+	wsPongWait = 2 * wsPingPeriod
+)
+
+var upgrader = websocket.Upgrader{}
+
+// wsRequest is what clients send: {"action": "subscribe", "leagues": [1],
+// "managers": [2]}, or "unsubscribe" with the same lists.
+type wsRequest struct {
+	Action   string `json:"action"`
+	Leagues  []int  `json:"leagues"`
+	Managers []int  `json:"managers"`
+}
+
+// wsMessage is what the server sends: a league's standings, a manager's live
+// gameweek or an error, told apart by Type.
+type wsMessage struct {
+	Version  int           `json:"version"`
+	Type     string        `json:"type"`
+	Gameweek int           `json:"gameweek,omitempty"`
+	League   *apiLeague    `json:"league,omitempty"`
+	Manager  *apiEntryLive `json:"manager,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// wsConn is one websocket client and what it is subscribed to.
+type wsConn struct {
+	ws   *websocket.Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+	// subscribes queues new subscriptions for scorePump to answer, so
+	// readPump keeps reading, and handling pongs, while they're scored.
+	subscribes chan wsRequest
+
+	mu       sync.Mutex
+	leagues  map[int]bool
+	managers map[int]bool
+}
+
+// wsClients scores each subscribed league and manager once per live update
+// and fans the result out to every connection subscribed to it.
+var wsClients = newUpdateHub(leagueMessage, managerMessage)
+
+func serveWS(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already answered with an error.
+		log.Println(err)
+		return
+	}
+	c := &wsConn{
+		ws:         ws,
+		send:       make(chan []byte, wsSendBuffer),
+		done:       make(chan struct{}),
+		subscribes: make(chan wsRequest, wsPendingSubscribes),
+		leagues:    make(map[int]bool),
+		managers:   make(map[int]bool),
+	}
+	wsClients.register(c)
+	defer func() {
+		c.close()
+		wsClients.unregister(c)
+	}()
+	go c.writePump()
+	go c.scorePump()
+	c.readPump()
+}
+
+// follows lists what the connection is subscribed to.
+func (c *wsConn) follows() (leagues, managers []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range c.leagues {
+		leagues = append(leagues, id)
+	}
+	for id := range c.managers {
+		managers = append(managers, id)
+	}
+	return leagues, managers
+}
+
+func leagueMessage(ctx context.Context, id int, gw *gameweek) []byte {
+	rows, truncated, err := getLeague(ctx, id, gw)
+	if err != nil {
+		return errorMessage(err)
+	}
+	league := newAPILeague(id, gw.Event, rows, truncated)
+	return encodeMessage(wsMessage{Version: apiVersion, Type: "league", Gameweek: gw.Event, League: &league})
+}
+
+func managerMessage(ctx context.Context, id int, gw *gameweek) []byte {
+	r, err := getManagerLive(ctx, id, gw)
+	if err != nil {
+		return errorMessage(err)
+	}
+	manager := newAPIEntryLive(r)
+	return encodeMessage(wsMessage{Version: apiVersion, Type: "manager", Gameweek: gw.Event, Manager: &manager})
+}
+
+func errorMessage(err error) []byte {
+	log.Println(err)
+	_, message := errorStatus(err)
+	return encodeMessage(wsMessage{Version: apiVersion, Type: "error", Error: message})
+}
+
+func encodeMessage(m wsMessage) []byte {
+	data, err := json.Marshal(m)
+	if err != nil {
+		log.Println(err)
+	}
+	return data
+}
+
+// deliver queues msg without blocking; a connection too slow to keep up is
+// dropped rather than holding up everyone else's updates.
+func (c *wsConn) deliver(msg []byte) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	default:
+		log.Println("websocket client too slow, dropping it")
+		c.close()
+	}
+}
+
+func (c *wsConn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
+
+// readPump handles subscribe and unsubscribe requests until the client goes
+// away or stops answering pings. New subscriptions are handed to scorePump to
+// answer with the current state; a subscribe that would take the connection
+// past wsMaxSubscriptions, or arrives with wsPendingSubscribes still
+// unanswered, is refused.
+func (c *wsConn) readPump() {
+	c.ws.SetReadLimit(wsMaxMessage)
+	c.ws.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		var req wsRequest
+		if err := c.ws.ReadJSON(&req); err != nil {
+			return
+		}
+		switch req.Action {
+		case "subscribe":
+			c.mu.Lock()
+			if c.subscriptions(req) > wsMaxSubscriptions {
+				c.mu.Unlock()
+				c.deliver(encodeMessage(wsMessage{Version: apiVersion, Type: "error", Error: fmt.Sprintf("at most %v subscriptions per connection", wsMaxSubscriptions)}))
+				continue
+			}
+			for _, id := range req.Leagues {
+				c.leagues[id] = true
+			}
+			for _, id := range req.Managers {
+				c.managers[id] = true
+			}
+			c.mu.Unlock()
+			select {
+			case c.subscribes <- req:
+			default:
+				c.deliver(encodeMessage(wsMessage{Version: apiVersion, Type: "error", Error: "too many subscribes waiting, try again shortly"}))
+			}
+		case "unsubscribe":
+			c.mu.Lock()
+			for _, id := range req.Leagues {
+				delete(c.leagues, id)
+			}
+			for _, id := range req.Managers {
+				delete(c.managers, id)
+			}
+			c.mu.Unlock()
+		default:
+			c.deliver(encodeMessage(wsMessage{Version: apiVersion, Type: "error", Error: "unknown action " + req.Action}))
+		}
+	}
+}
+
+// subscriptions is how many leagues and managers c would follow after req is
+// subscribed to. c.mu must be held.
+func (c *wsConn) subscriptions(req wsRequest) int {
+	leagues := make(map[int]bool, len(c.leagues))
+	for id := range c.leagues {
+		leagues[id] = true
+	}
+	for _, id := range req.Leagues {
+		leagues[id] = true
+	}
+	managers := make(map[int]bool, len(c.managers))
+	for id := range c.managers {
+		managers[id] = true
+	}
+	for _, id := range req.Managers {
+		managers[id] = true
+	}
+	return len(leagues) + len(managers)
+}
+
+// scorePump answers subscribes, one at a time, until the connection closes.
+func (c *wsConn) scorePump() {
+	for {
+		select {
+		case req := <-c.subscribes:
+			c.sendCurrent(req)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// sendCurrent answers a subscribe with the current state of each league and
+// manager in it, each scored with its own hubScoreTimeout.
+func (c *wsConn) sendCurrent(req wsRequest) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Stop scoring for a client that has gone.
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	gwCtx, gwCancel := context.WithTimeout(ctx, hubScoreTimeout)
+	gw, err := getGameweek(gwCtx, getCurrentGw())
+	gwCancel()
+	if err != nil {
+		c.deliver(errorMessage(err))
+		return
+	}
+	var jobs []hubJob
+	for _, id := range req.Leagues {
+		jobs = append(jobs, hubJob{id, leagueMessage, []follower{c}})
+	}
+	for _, id := range req.Managers {
+		jobs = append(jobs, hubJob{id, managerMessage, []follower{c}})
+	}
+	scoreJobs(ctx, gw, jobs)
+}
+
+func (c *wsConn) writePump() {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	defer c.close()
+	for {
+		select {
+		case msg := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.ws.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			c.ws.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
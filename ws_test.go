@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lewislebentz/Go-FPL/fpl"
+)
+
+// TestWSKeepsReadingWhileScoring subscribes to a league that takes several
+// pong waits to score and checks the connection is still open afterwards.
+func TestWSKeepsReadingWhileScoring(t *testing.T) {
+	setTestBootstrap()
+	api := leagueAPI(120)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/leagues-classic/") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		api.ServeHTTP(w, r)
+	}))
+	defer slow.Close()
+	savedClient, savedPing, savedPong := client, wsPingPeriod, wsPongWait
+	client = fpl.NewClient(slow.URL, nil)
+	client.Limiter = nil
+	wsPingPeriod, wsPongWait = 20*time.Millisecond, 100*time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(serveWS))
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		// Wait for the server side to go before putting the timings back.
+		conn.Close()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if wsClients.size() == 0 {
+				break
+			}
+		}
+		client, wsPingPeriod, wsPongWait = savedClient, savedPing, savedPong
+	}()
+
+	// Reading answers the server's pings, as a browser would.
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if err := conn.WriteJSON(wsRequest{Action: "subscribe", Leagues: []int{1}}); err != nil {
+		t.Fatal(err)
+	}
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("reading the league: %v", err)
+	}
+	if msg.Type != "league" || msg.League == nil {
+		t.Fatalf("got %+v, want the league", msg)
+	}
+
+	if err := conn.WriteJSON(wsRequest{Action: "bogus"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("connection closed after scoring: %v", err)
+	}
+	if msg.Type != "error" {
+		t.Errorf("got %+v, want an error", msg)
+	}
+}